@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"golang.org/x/term"
+)
+
+// Users are either ordinary users or admins. The first user to register
+// becomes an admin; on installs upgraded from before roles, the operator
+// makes an account an admin with "gator promote". Admins can promote others
+// with "gator setrole".
+// Commands that delete other users' data are admin-only and ask for
+// confirmation unless --yes is given.
+
+const (
+	roleUser  = "user"
+	roleAdmin = "admin"
+)
+
+// newUserRole returns the role a user registering now gets.
+func newUserRole(ctx context.Context, db *database.Queries) (string, error) {
+	n, err := db.CountUsers(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error counting users: %v", err)
+	}
+	if n == 0 {
+		return roleAdmin, nil
+	}
+	return roleUser, nil
+}
+
+func middlewareAdmin(handler func(s *state, cmd command, user database.User) error) func(*state, command, database.User) error {
+	return func(s *state, cmd command, user database.User) error {
+		if user.Role != roleAdmin {
+			return fmt.Errorf("%s can only be run by an admin", cmd.name)
+		}
+		return handler(s, cmd, user)
+	}
+}
+
+func setDestructiveFlags(fs *flag.FlagSet) {
+	fs.Bool("yes", false, "don't ask for confirmation")
+	fs.String("snapshot", "", "save a pg_dump of the database to this file first")
+}
+
+// confirmDestructive asks the user to confirm what, unless --yes was given,
+// and then takes the snapshot asked for with --snapshot.
+func confirmDestructive(s *state, cmd command, what string) error {
+	if !cmd.flagBool("yes") {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("refusing to %s without --yes when stdin is not a terminal", what)
+		}
+		fmt.Fprintf(os.Stderr, "This will %s. Continue? [y/N] ", what)
+		answer, err := stdinLines.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("error reading answer: %v", err)
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+		default:
+			return fmt.Errorf("aborted")
+		}
+	}
+	if path := cmd.flagString("snapshot"); path != "" {
+		return snapshot(s, path)
+	}
+	return nil
+}
+
+// snapshot saves the database to path with pg_dump, so a destructive
+// command can be undone with pg_restore.
+func snapshot(s *state, path string) error {
+	out, err := exec.Command("pg_dump", "--format=custom", "--file="+path, "--dbname="+s.cfg.DatabaseURL).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error taking snapshot with pg_dump: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	if s.out.isText() {
+		fmt.Fprintf(os.Stderr, "Saved snapshot to %s, restore it with pg_restore --clean\n", path)
+	}
+	return nil
+}
+
+// handlePromote makes a user an admin on an install without one. It is for
+// the operator bootstrapping an upgraded install: it needs direct access to
+// the database and has no web or API counterpart, so signing up through
+// "gator serve" never leads to it. The user needs a password, so
+// passwordless accounts from before gator had passwords can't be promoted.
+func handlePromote(s *state, cmd command) error {
+	user, err := s.db.GetUser(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting user: %v", err)
+	}
+	// Under serializable isolation two concurrent promotions can't both
+	// see no admin and succeed.
+	tx, err := s.conn.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	n, err := s.db.WithTx(tx).PromoteFirstAdmin(context.Background(), database.PromoteFirstAdminParams{
+		ID:        user.ID,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error setting role: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error setting role: %v", err)
+	}
+	if n == 0 {
+		if user.HashedPassword == "" {
+			return fmt.Errorf("%s has no password, set one with \"gator passwd\" first", user.Name)
+		}
+		return fmt.Errorf("there is already an admin, ask them to run \"gator setrole %s admin\"", user.Name)
+	}
+	user.Role = roleAdmin
+	return s.out.print(newUserView(user, s.cfg.GetUser()), func() {
+		fmt.Printf("%s is now an admin\n", user.Name)
+	})
+}
+
+func handleSetRole(s *state, cmd command, admin database.User) error {
+	role := cmd.args[1]
+	if role != roleUser && role != roleAdmin {
+		return fmt.Errorf("unknown role %q: must be %s or %s", role, roleUser, roleAdmin)
+	}
+	user, err := s.db.GetUser(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting user: %v", err)
+	}
+	if user.ID == admin.ID && role != roleAdmin {
+		return fmt.Errorf("you cannot remove your own admin role, ask another admin")
+	}
+	err = s.db.SetUserRole(context.Background(), database.SetUserRoleParams{
+		ID:        user.ID,
+		Role:      role,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error setting role: %v", err)
+	}
+	user.Role = role
+	return s.out.print(newUserView(user, s.cfg.GetUser()), func() {
+		fmt.Printf("%s is now %s\n", user.Name, articleRole(role))
+	})
+}
+
+func articleRole(role string) string {
+	if role == roleAdmin {
+		return "an admin"
+	}
+	return "a user"
+}
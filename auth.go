@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+)
+
+// Passwords are stored as bcrypt hashes. Logging in creates a session whose
+// token is kept in the config file; API tokens let scripts and HTTP clients
+// act as a user without a password. Only SHA-256 hashes of session and API
+// tokens are stored in the database.
+
+const (
+	sessionTTL     = 30 * 24 * time.Hour
+	apiTokenPrefix = "gtr_"
+	// tokenEnv names the environment variable scripts can set to an API
+	// token instead of logging in.
+	tokenEnv       = "GATOR_TOKEN"
+	minPasswordLen = 8
+)
+
+var errNotLoggedIn = errors.New("not logged in")
+
+// stdinLines reads stdin line by line when it is not a terminal. The shell
+// and the password and confirmation prompts share it, so that a prompt reads
+// the line after the command that asked for it and not one already buffered.
+var stdinLines = bufio.NewReader(os.Stdin)
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("error hashing password: %v", err)
+	}
+	return string(hash), nil
+}
+
+// dummyPasswordHash is compared against when a user has no password, so
+// that checkPassword takes as long for unknown and passwordless users as for
+// real ones and its timing doesn't reveal which names exist.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("gator"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// checkPassword reports whether password is user's. Pass the zero User for a
+// user that doesn't exist.
+func checkPassword(user database.User, password string) bool {
+	if user.HashedPassword == "" {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(password)) == nil
+}
+
+// readPassword prompts on stderr and reads a password without echoing it.
+// When stdin is not a terminal it reads one line instead.
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("error reading password: %v", err)
+		}
+		return string(password), nil
+	}
+	line, err := stdinLines.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("error reading password: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readNewPassword asks for a new password twice and checks they match.
+func readNewPassword() (string, error) {
+	password, err := readPassword("New password: ")
+	if err != nil {
+		return "", err
+	}
+	if len(password) < minPasswordLen {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLen)
+	}
+	confirm, err := readPassword("Confirm password: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != password {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return password, nil
+}
+
+// createSession stores a new session for user and returns its token.
+func createSession(ctx context.Context, db *database.Queries, user database.User) (string, time.Time, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expires := time.Now().Add(sessionTTL)
+	err = db.CreateSession(ctx, database.CreateSessionParams{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+		ExpiresAt: expires,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error creating session: %v", err)
+	}
+	return token, expires, nil
+}
+
+// startSession logs user in on this machine.
+func startSession(s *state, user database.User) error {
+	token, _, err := createSession(context.Background(), s.db, user)
+	if err != nil {
+		return err
+	}
+	return s.cfg.SetSession(user.Name, token)
+}
+
+// userFromAPIToken returns the owner of an API token and records its use.
+func userFromAPIToken(ctx context.Context, db *database.Queries, token string) (database.User, error) {
+	hash := hashToken(token)
+	user, err := db.GetUserByAPIToken(ctx, hash)
+	if err != nil {
+		return database.User{}, err
+	}
+	err = db.TouchAPIToken(ctx, database.TouchAPITokenParams{
+		TokenHash:  hash,
+		LastUsedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return database.User{}, fmt.Errorf("error updating API token: %v", err)
+	}
+	return user, nil
+}
+
+// currentUser returns the user commands act for: the owner of the API token
+// in $GATOR_TOKEN if set, or else the user logged in with "gator login".
+func currentUser(ctx context.Context, s *state) (database.User, error) {
+	if token := os.Getenv(tokenEnv); token != "" {
+		user, err := userFromAPIToken(ctx, s.db, token)
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.User{}, fmt.Errorf("invalid API token in $%s", tokenEnv)
+		}
+		return user, err
+	}
+	if s.cfg.GetSessionToken() == "" {
+		return database.User{}, errNotLoggedIn
+	}
+	user, err := s.db.GetUserBySessionToken(ctx, hashToken(s.cfg.GetSessionToken()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, fmt.Errorf("session expired, run \"gator login %s\" again", s.cfg.GetUser())
+	}
+	return user, err
+}
+
+func handleLogout(s *state, _ command) error {
+	if token := s.cfg.GetSessionToken(); token != "" {
+		if err := s.db.DeleteSession(context.Background(), hashToken(token)); err != nil {
+			return fmt.Errorf("error deleting session: %v", err)
+		}
+	}
+	if err := s.cfg.ClearSession(); err != nil {
+		return err
+	}
+	return s.out.print(messageView{Message: "Logged out"}, func() {
+		fmt.Println("Logged out")
+	})
+}
+
+// handlePasswd changes the user's password and ends every session, including
+// the current one, before logging back in with a new session. Admins can
+// give another user's name to set their password, which is also how users
+// created before gator had passwords are unlocked.
+func handlePasswd(s *state, cmd command, user database.User) error {
+	if len(cmd.args) > 0 && cmd.args[0] != user.Name {
+		return handleSetPassword(s, cmd.args[0], user)
+	}
+	current, err := readPassword("Current password: ")
+	if err != nil {
+		return err
+	}
+	if !checkPassword(user, current) {
+		return fmt.Errorf("incorrect password")
+	}
+	password, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	err = s.db.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		ID:             user.ID,
+		HashedPassword: hash,
+		UpdatedAt:      time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error setting password: %v", err)
+	}
+	if err = s.db.DeleteSessionsForUser(context.Background(), user.ID); err != nil {
+		return fmt.Errorf("error ending sessions: %v", err)
+	}
+	if err = startSession(s, user); err != nil {
+		return err
+	}
+	return s.out.print(messageView{Message: "Password changed, other sessions have been logged out"}, func() {
+		fmt.Println("Password changed, other sessions have been logged out")
+	})
+}
+
+// handleSetPassword lets an admin set another user's password and log them
+// out everywhere.
+func handleSetPassword(s *state, name string, admin database.User) error {
+	if admin.Role != roleAdmin {
+		return fmt.Errorf("only an admin can set another user's password")
+	}
+	user, err := s.db.GetUser(context.Background(), name)
+	if err != nil {
+		return fmt.Errorf("error getting user: %v", err)
+	}
+	password, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	err = s.db.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		ID:             user.ID,
+		HashedPassword: hash,
+		UpdatedAt:      time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error setting password: %v", err)
+	}
+	if err = s.db.DeleteSessionsForUser(context.Background(), user.ID); err != nil {
+		return fmt.Errorf("error ending sessions: %v", err)
+	}
+	msg := fmt.Sprintf("Set the password of %s, who can now log in with it", user.Name)
+	return s.out.print(messageView{Message: msg}, func() {
+		fmt.Println(msg)
+	})
+}
+
+func handleAddToken(s *state, cmd command, user database.User) error {
+	secret, err := newToken()
+	if err != nil {
+		return err
+	}
+	token := apiTokenPrefix + secret
+	created, err := s.db.CreateAPIToken(context.Background(), database.CreateAPITokenParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      cmd.args[0],
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating API token (is the name %q already used?): %v", cmd.args[0], err)
+	}
+	view := newTokenView(created)
+	view.Token = token
+	return s.out.print(view, func() {
+		fmt.Printf("Created API token %s:\n\n    %s\n\n", created.Name, token)
+		fmt.Println("It won't be shown again. Send it as \"Authorization: Bearer <token>\" or set $" + tokenEnv + ".")
+	})
+}
+
+func handleTokens(s *state, _ command, user database.User) error {
+	tokens, err := s.db.GetAPITokensForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting API tokens: %v", err)
+	}
+	views := make([]tokenView, 0, len(tokens))
+	for _, token := range tokens {
+		views = append(views, newTokenView(token))
+	}
+	return s.out.print(views, func() {
+		if len(views) == 0 {
+			fmt.Println("No API tokens")
+			return
+		}
+		for _, token := range views {
+			lastUsed := "never used"
+			if token.LastUsedAt != nil {
+				lastUsed = "last used " + token.LastUsedAt.Format(time.DateTime)
+			}
+			fmt.Printf("* %s (created %s, %s)\n", token.Name, token.CreatedAt.Format(time.DateTime), lastUsed)
+		}
+	})
+}
+
+func handleRevokeToken(s *state, cmd command, user database.User) error {
+	n, err := s.db.DeleteAPIToken(context.Background(), database.DeleteAPITokenParams{
+		UserID: user.ID,
+		Name:   cmd.args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("error revoking API token: %v", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("no API token named %q", cmd.args[0])
+	}
+	msg := fmt.Sprintf("Revoked API token %s", cmd.args[0])
+	return s.out.print(messageView{Message: msg}, func() {
+		fmt.Println(msg)
+	})
+}
+
+func completeTokenNames(s *state) ([]candidate, error) {
+	user, err := currentUser(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.db.GetAPITokensForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidate, 0, len(tokens))
+	for _, token := range tokens {
+		candidates = append(candidates, candidate{value: token.Name})
+	}
+	return candidates, nil
+}
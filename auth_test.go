@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/awbalessa/gator/internal/database"
+)
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		user     database.User
+		password string
+		want     bool
+	}{
+		{name: "correct", user: database.User{HashedPassword: hash}, password: "correct horse", want: true},
+		{name: "wrong", user: database.User{HashedPassword: hash}, password: "battery staple"},
+		{name: "passwordless", user: database.User{Name: "old"}, password: ""},
+		{name: "unknown user", user: database.User{}, password: "gator"},
+	}
+	for _, tt := range tests {
+		if got := checkPassword(tt.user, tt.password); got != tt.want {
+			t.Errorf("%s: checkPassword() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
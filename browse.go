@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	sortPublished = "published"
+	sortFetched   = "fetched"
+)
+
+// postFilter narrows down the posts shown to a user. Pagination can be done
+// either with an offset or with a cursor returned from a previous page; the
+// cursor stays stable while new posts are being added.
+type postFilter struct {
+	feedID     uuid.NullUUID
+	folder     sql.NullString
+	tsQuery    sql.NullString
+	since      sql.NullTime
+	until      sql.NullTime
+	unreadOnly bool
+	sortBy     string
+	cursor     *postCursor
+	limit      int32
+	offset     int32
+}
+
+type postCursor struct {
+	time time.Time
+	id   uuid.UUID
+}
+
+func queryPosts(ctx context.Context, db *database.Queries, userID uuid.UUID, f postFilter) ([]postView, error) {
+	var cursorTime sql.NullTime
+	var cursorID uuid.NullUUID
+	if f.cursor != nil {
+		cursorTime = sql.NullTime{Time: f.cursor.time, Valid: true}
+		cursorID = uuid.NullUUID{UUID: f.cursor.id, Valid: true}
+	}
+
+	switch f.sortBy {
+	case sortPublished, "":
+		rows, err := db.BrowsePostsByPublished(ctx, database.BrowsePostsByPublishedParams{
+			UserID:     userID,
+			FeedID:     f.feedID,
+			Since:      f.since,
+			Until:      f.until,
+			UnreadOnly: f.unreadOnly,
+			CursorTime: cursorTime,
+			CursorID:   cursorID,
+			Folder:     f.folder,
+			TsQuery:    f.tsQuery,
+			Limit:      f.limit,
+			Offset:     f.offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		posts := make([]postView, 0, len(rows))
+		for _, row := range rows {
+			posts = append(posts, newBrowsedPostView(row))
+		}
+		return posts, nil
+	case sortFetched:
+		rows, err := db.BrowsePostsByFetched(ctx, database.BrowsePostsByFetchedParams{
+			UserID:     userID,
+			FeedID:     f.feedID,
+			Since:      f.since,
+			Until:      f.until,
+			UnreadOnly: f.unreadOnly,
+			CursorTime: cursorTime,
+			CursorID:   cursorID,
+			Folder:     f.folder,
+			TsQuery:    f.tsQuery,
+			Limit:      f.limit,
+			Offset:     f.offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		posts := make([]postView, 0, len(rows))
+		for _, row := range rows {
+			// Both sort orders return the same columns.
+			posts = append(posts, newBrowsedPostView(database.BrowsePostsByPublishedRow(row)))
+		}
+		return posts, nil
+	default:
+		return nil, fmt.Errorf("unknown sort order %q: must be %s or %s", f.sortBy, sortPublished, sortFetched)
+	}
+}
+
+func newBrowsedPostView(row database.BrowsePostsByPublishedRow) postView {
+	return postView{
+		ID:          row.ID,
+		Title:       row.Title,
+		Url:         row.Url,
+		Description: row.Description,
+		Content:     postContent(row.ContentText, row.Description),
+		PublishedAt: row.PublishedAt,
+		FetchedAt:   row.CreatedAt,
+		FeedID:      row.FeedID,
+		FeedName:    row.FeedName,
+		Read:        row.IsRead,
+		Starred:     row.IsStarred,
+	}
+}
+
+// nextCursor returns the cursor pointing just past the last post of a page.
+func nextCursor(posts []postView, sortBy string) string {
+	if len(posts) == 0 {
+		return ""
+	}
+	last := posts[len(posts)-1]
+	c := postCursor{time: last.PublishedAt, id: last.ID}
+	if sortBy == sortFetched {
+		c.time = last.FetchedAt
+	}
+	return c.encode()
+}
+
+func (c postCursor) encode() string {
+	raw := c.time.UTC().Format(time.RFC3339Nano) + "|" + c.id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*postCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+	timePart, idPart, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, timePart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor time: %v", err)
+	}
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor ID: %v", err)
+	}
+	return &postCursor{time: t, id: id}, nil
+}
+
+// parseTimeFlag accepts an absolute date ("2006-01-02"), a timestamp
+// (RFC 3339) or a duration relative to now ("36h", "7d").
+func parseTimeFlag(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return sql.NullTime{Time: time.Now().AddDate(0, 0, -n), Valid: true}, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return sql.NullTime{Time: time.Now().Add(-d), Valid: true}, nil
+	}
+
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02T15:04",
+		"2006-01-02 15:04",
+		time.DateOnly,
+	}
+	for _, format := range formats {
+		t, err := time.ParseInLocation(format, value, time.Local)
+		if err == nil {
+			return sql.NullTime{Time: t, Valid: true}, nil
+		}
+	}
+	return sql.NullTime{}, fmt.Errorf("unable to parse time %q", value)
+}
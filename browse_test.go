@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []postCursor{
+		{time: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), id: uuid.MustParse("6f1c2b1e-4a5d-4c3b-9a8e-1f2d3c4b5a69")},
+		{time: time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC), id: uuid.New()},
+		{time: time.Date(2023, 12, 31, 23, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), id: uuid.New()},
+		{time: time.Time{}, id: uuid.Nil},
+	}
+	for _, want := range tests {
+		got, err := decodeCursor(want.encode())
+		if err != nil {
+			t.Errorf("decodeCursor(%v.encode()) returned error: %v", want, err)
+			continue
+		}
+		if !got.time.Equal(want.time) || got.id != want.id {
+			t.Errorf("decodeCursor(%v.encode()) = %v, want %v", want, *got, want)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("2024-03-01T12:30:00Z|x"))},
+		{"no separator", encode("2024-03-01T12:30:00Z")},
+		{"bad time", encode("yesterday|6f1c2b1e-4a5d-4c3b-9a8e-1f2d3c4b5a69")},
+		{"bad ID", encode("2024-03-01T12:30:00Z|not-a-uuid")},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		if got, err := decodeCursor(tt.cursor); err == nil {
+			t.Errorf("%s: decodeCursor(%q) = %v, want an error", tt.name, tt.cursor, *got)
+		}
+	}
+}
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		value   string
+		want    time.Time
+		approx  bool
+		valid   bool
+		wantErr bool
+	}{
+		{value: "", valid: false},
+		{value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), valid: true},
+		{value: "2024-03-01 14:05", want: time.Date(2024, 3, 1, 14, 5, 0, 0, time.Local), valid: true},
+		{value: "2024-03-01T14:05", want: time.Date(2024, 3, 1, 14, 5, 0, 0, time.Local), valid: true},
+		{value: "2024-03-01T14:05:00Z", want: time.Date(2024, 3, 1, 14, 5, 0, 0, time.UTC), valid: true},
+		{value: "2024-03-01T14:05:00+02:00", want: time.Date(2024, 3, 1, 12, 5, 0, 0, time.UTC), valid: true},
+		{value: "36h", want: now.Add(-36 * time.Hour), approx: true, valid: true},
+		{value: "90m", want: now.Add(-90 * time.Minute), approx: true, valid: true},
+		{value: "7d", want: now.AddDate(0, 0, -7), approx: true, valid: true},
+		{value: "xd", wantErr: true},
+		{value: "last week", wantErr: true},
+		{value: "2024-13-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeFlag(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTimeFlag(%q) = %v, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTimeFlag(%q) returned error: %v", tt.value, err)
+			continue
+		}
+		if got.Valid != tt.valid {
+			t.Errorf("parseTimeFlag(%q).Valid = %v, want %v", tt.value, got.Valid, tt.valid)
+			continue
+		}
+		if !tt.valid {
+			continue
+		}
+		if tt.approx {
+			if d := got.Time.Sub(tt.want); d < -time.Minute || d > time.Minute {
+				t.Errorf("parseTimeFlag(%q) = %v, want about %v", tt.value, got.Time, tt.want)
+			}
+		} else if !got.Time.Equal(tt.want) {
+			t.Errorf("parseTimeFlag(%q) = %v, want %v", tt.value, got.Time, tt.want)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+)
+
+type command struct {
+	name  string
+	args  []string
+	flags *flag.FlagSet
+}
+
+// commandInfo describes a command: how it is dispatched, which flags and how
+// many positional arguments it accepts, and what help prints for it.
+// Commands that act on behalf of the logged in user set userHandler instead
+// of handler, and admin-only commands also set adminOnly. Stateless
+// commands run without a config or database connection. completeArgs and
+// completeFlags feed shell completion; completeArgs has a completer per
+// positional argument.
+type commandInfo struct {
+	name          string
+	summary       string
+	usage         string
+	minArgs       int
+	maxArgs       int
+	hidden        bool
+	adminOnly     bool
+	rawArgs       bool
+	stateless     bool
+	setFlags      func(fs *flag.FlagSet)
+	handler       func(*state, command) error
+	userHandler   func(*state, command, database.User) error
+	completeArgs  []completer
+	completeFlags map[string]completer
+}
+
+// unlimitedArgs is used as maxArgs for commands taking any number of
+// positional arguments.
+const unlimitedArgs = -1
+
+func (info commandInfo) requiresLogin() bool {
+	return info.userHandler != nil
+}
+
+func (info commandInfo) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(info.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if info.setFlags != nil {
+		info.setFlags(fs)
+	}
+	return fs
+}
+
+func (info commandInfo) synopsis() string {
+	parts := []string{"gator", info.name}
+	if info.setFlags != nil {
+		parts = append(parts, "[flags]")
+	}
+	if info.usage != "" {
+		parts = append(parts, info.usage)
+	}
+	return strings.Join(parts, " ")
+}
+
+type commands struct {
+	cmdToInfo map[string]commandInfo
+}
+
+func (c *commands) register(info commandInfo) {
+	c.cmdToInfo[info.name] = info
+}
+
+// isStateless reports whether name is a command that can run with a nil
+// state.
+func (c *commands) isStateless(name string) bool {
+	return c.cmdToInfo[name].stateless
+}
+
+func (c *commands) run(s *state, cmd command) error {
+	info, ok := c.cmdToInfo[cmd.name]
+	if !ok {
+		return c.unknownCommandError(cmd.name)
+	}
+
+	fs := info.flagSet()
+	var global globalOptions
+	if s != nil && !info.rawArgs {
+		addGlobalFlags(fs, &global)
+	}
+	args, err := cmd.args, error(nil)
+	if !info.rawArgs {
+		args, err = parseInterspersed(fs, cmd.args)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		c.printCommandHelp(os.Stdout, info)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s: %v\nusage: %s", info.name, err, info.synopsis())
+	}
+	if err = info.checkArgs(args); err != nil {
+		return fmt.Errorf("%s: %v\nusage: %s", info.name, err, info.synopsis())
+	}
+	if s != nil {
+		if s, err = withGlobalFlags(s, fs, global); err != nil {
+			return err
+		}
+	}
+
+	cmd.args = args
+	cmd.flags = fs
+	if info.requiresLogin() {
+		handler := info.userHandler
+		if info.adminOnly {
+			handler = middlewareAdmin(handler)
+		}
+		return middlewareLoggedIn(handler)(s, cmd)
+	}
+	return info.handler(s, cmd)
+}
+
+func (info commandInfo) checkArgs(args []string) error {
+	if len(args) < info.minArgs {
+		return fmt.Errorf("expected at least %d %s, got %d", info.minArgs, pluralArgs(info.minArgs), len(args))
+	}
+	if info.maxArgs != unlimitedArgs && len(args) > info.maxArgs {
+		if info.maxArgs == 0 {
+			return fmt.Errorf("takes no arguments, got %d", len(args))
+		}
+		return fmt.Errorf("expected at most %d %s, got %d", info.maxArgs, pluralArgs(info.maxArgs), len(args))
+	}
+	return nil
+}
+
+func pluralArgs(n int) string {
+	if n == 1 {
+		return "argument"
+	}
+	return "arguments"
+}
+
+// parseInterspersed parses flags appearing anywhere among the arguments and
+// returns the positional ones. Everything after "--" is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		consumed := len(args) - fs.NArg()
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, fs.Args()...), nil
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func (c *commands) unknownCommandError(name string) error {
+	if suggestions := c.suggest(name); len(suggestions) > 0 {
+		return fmt.Errorf("unknown command %q, did you mean %s?", name, strings.Join(suggestions, " or "))
+	}
+	return fmt.Errorf("unknown command %q, run \"gator help\" for a list of commands", name)
+}
+
+// suggest returns registered command names close to name, for typos.
+func (c *commands) suggest(name string) []string {
+	var suggestions []string
+	for candidate, info := range c.cmdToInfo {
+		if info.hidden {
+			continue
+		}
+		if levenshtein(name, candidate) <= 2 || (len(name) > 2 && strings.HasPrefix(candidate, name)) {
+			suggestions = append(suggestions, fmt.Sprintf("%q", candidate))
+		}
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func (c *commands) sortedNames() []string {
+	names := make([]string, 0, len(c.cmdToInfo))
+	for name, info := range c.cmdToInfo {
+		if info.hidden {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *commands) handleHelp(_ *state, cmd command) error {
+	if len(cmd.args) == 1 {
+		info, ok := c.cmdToInfo[cmd.args[0]]
+		if !ok {
+			return c.unknownCommandError(cmd.args[0])
+		}
+		c.printCommandHelp(os.Stdout, info)
+		return nil
+	}
+	c.printHelp(os.Stdout)
+	return nil
+}
+
+func (c *commands) printHelp(w io.Writer) {
+	fmt.Fprintln(w, "Usage: gator [global flags] <command> [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	names := c.sortedNames()
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, name, c.cmdToInfo[name].summary)
+	}
+	fmt.Fprintln(w, "\nGlobal flags, which may also follow the command:")
+	global := globalFlagSet(new(globalOptions))
+	global.SetOutput(w)
+	global.PrintDefaults()
+	fmt.Fprintln(w, "\nRun \"gator help <command>\" for details about a command.")
+}
+
+func (c *commands) printCommandHelp(w io.Writer, info commandInfo) {
+	fmt.Fprintf(w, "Usage: %s\n\n%s\n", info.synopsis(), info.summary)
+	if info.requiresLogin() {
+		fmt.Fprintln(w, "\nRequires a logged in user.")
+	}
+	fs := info.flagSet()
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+// Accessors for flags declared by a command's setFlags. Asking for a flag the
+// command does not declare is a programming error and panics.
+
+func (cmd command) flagValue(name string) any {
+	f := cmd.flags.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("command %s has no flag %q", cmd.name, name))
+	}
+	return f.Value.(flag.Getter).Get()
+}
+
+func (cmd command) flagString(name string) string {
+	return cmd.flagValue(name).(string)
+}
+
+func (cmd command) flagInt(name string) int {
+	return cmd.flagValue(name).(int)
+}
+
+func (cmd command) flagBool(name string) bool {
+	return cmd.flagValue(name).(bool)
+}
+
+func (cmd command) flagDuration(name string) time.Duration {
+	return cmd.flagValue(name).(time.Duration)
+}
+
+// flagPassed reports whether a flag was given on the command line rather
+// than left at its default.
+func (cmd command) flagPassed(name string) bool {
+	set := false
+	cmd.flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
+	return func(s *state, cmd command) error {
+		user, err := currentUser(context.Background(), s)
+		if errors.Is(err, errNotLoggedIn) {
+			return fmt.Errorf("%s requires a logged in user, run \"gator login <name>\" first", cmd.name)
+		} else if err != nil {
+			return fmt.Errorf("error getting user: %v", err)
+		}
+
+		return handler(s, cmd, user)
+	}
+}
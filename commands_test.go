@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/awbalessa/gator/internal/config"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     []string
+		wantFeed string
+		wantYes  bool
+		wantErr  bool
+	}{
+		{name: "no arguments", args: nil, want: nil},
+		{name: "positional only", args: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "flags first", args: []string{"--feed", "x", "a"}, want: []string{"a"}, wantFeed: "x"},
+		{name: "flags last", args: []string{"a", "--yes"}, want: []string{"a"}, wantYes: true},
+		{name: "flags between", args: []string{"a", "-feed=x", "b", "-yes"}, want: []string{"a", "b"}, wantFeed: "x", wantYes: true},
+		{name: "double dash", args: []string{"a", "--", "--yes", "b"}, want: []string{"a", "--yes", "b"}},
+		{name: "single dash is positional", args: []string{"-", "a"}, want: []string{"-", "a"}},
+		{name: "unknown flag", args: []string{"a", "--nope"}, wantErr: true},
+		{name: "missing flag value", args: []string{"a", "--feed"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			feed := fs.String("feed", "", "")
+			yes := fs.Bool("yes", false, "")
+
+			got, err := parseInterspersed(fs, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseInterspersed(%q) = %q, want an error", tt.args, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseInterspersed(%q) returned error: %v", tt.args, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseInterspersed(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+			if *feed != tt.wantFeed || *yes != tt.wantYes {
+				t.Errorf("parseInterspersed(%q) set feed=%q yes=%v, want feed=%q yes=%v",
+					tt.args, *feed, *yes, tt.wantFeed, tt.wantYes)
+			}
+		})
+	}
+}
+
+func TestRunGlobalFlagsAfterCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantArgs   []string
+		wantOutput string
+		wantErr    bool
+	}{
+		{name: "none", args: []string{"a"}, wantArgs: []string{"a"}, wantOutput: outputText},
+		{name: "after command", args: []string{"--output", "json"}, wantArgs: nil, wantOutput: outputJSON},
+		{name: "shorthand between arguments", args: []string{"a", "-o=json", "b"}, wantArgs: []string{"a", "b"}, wantOutput: outputJSON},
+		{name: "with command flags", args: []string{"--yes", "a", "--output", "json"}, wantArgs: []string{"a"}, wantOutput: outputJSON},
+		{name: "after double dash", args: []string{"--", "--output", "json"}, wantArgs: []string{"--output", "json"}, wantOutput: outputText},
+		{name: "unknown format", args: []string{"--output", "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []string
+			var gotOutput string
+			cmds := commands{cmdToInfo: make(map[string]commandInfo)}
+			cmds.register(commandInfo{
+				name:    "test",
+				maxArgs: unlimitedArgs,
+				setFlags: func(fs *flag.FlagSet) {
+					fs.Bool("yes", false, "")
+				},
+				handler: func(s *state, cmd command) error {
+					gotArgs = cmd.args
+					gotOutput = s.out.format
+					return nil
+				},
+			})
+			out, err := newPrinter(outputText, os.Stdout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &state{cfg: &config.Config{}, out: out, opts: globalOptions{output: outputText}}
+
+			err = cmds.run(s, command{name: "test", args: tt.args})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("run(%q) succeeded, want an error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run(%q) returned error: %v", tt.args, err)
+			}
+			if !slices.Equal(gotArgs, tt.wantArgs) {
+				t.Errorf("run(%q) passed arguments %q, want %q", tt.args, gotArgs, tt.wantArgs)
+			}
+			if gotOutput != tt.wantOutput {
+				t.Errorf("run(%q) used output %q, want %q", tt.args, gotOutput, tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Shell completion works by having the generated scripts call the hidden
+// __complete command with the words typed so far. It prints one candidate
+// per line, optionally followed by a tab and a description, so commands,
+// flags and values stored in the database are all completed from one place.
+
+type candidate struct {
+	value       string
+	description string
+}
+
+// completer lists the values an argument or flag can take.
+type completer func(s *state) ([]candidate, error)
+
+func staticCompleter(values ...string) completer {
+	return func(*state) ([]candidate, error) {
+		candidates := make([]candidate, 0, len(values))
+		for _, v := range values {
+			candidates = append(candidates, candidate{value: v})
+		}
+		return candidates, nil
+	}
+}
+
+func completeFeedURLs(s *state) ([]candidate, error) {
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidate, 0, len(feeds))
+	for _, feed := range feeds {
+		candidates = append(candidates, candidate{value: feed.Url, description: feed.Name})
+	}
+	return candidates, nil
+}
+
+func completeFollowedFeedURLs(s *state) ([]candidate, error) {
+	user, err := currentUser(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidate, 0, len(follows))
+	for _, follow := range follows {
+		candidates = append(candidates, candidate{value: follow.FeedUrl, description: follow.FeedName})
+	}
+	return candidates, nil
+}
+
+func completeUsernames(s *state) ([]candidate, error) {
+	users, err := s.db.GetUsers(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidate, 0, len(users))
+	for _, user := range users {
+		candidates = append(candidates, candidate{value: user.Name})
+	}
+	return candidates, nil
+}
+
+func (c *commands) completeCommandNames(*state) ([]candidate, error) {
+	names := c.sortedNames()
+	candidates := make([]candidate, 0, len(names))
+	for _, name := range names {
+		candidates = append(candidates, candidate{value: name, description: c.cmdToInfo[name].summary})
+	}
+	return candidates, nil
+}
+
+func (c *commands) handleComplete(s *state, cmd command) error {
+	for _, cand := range c.complete(s, cmd.args) {
+		if cand.description == "" {
+			fmt.Println(cand.value)
+		} else {
+			fmt.Printf("%s\t%s\n", cand.value, cand.description)
+		}
+	}
+	return nil
+}
+
+// complete returns the candidates for the last of words, which are the
+// arguments typed after "gator" with the word being completed last.
+func (c *commands) complete(s *state, words []string) []candidate {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	global := globalFlagSet(new(globalOptions))
+	for i := 0; i < len(words)-1; i++ {
+		if strings.HasPrefix(words[i], "-") {
+			if flagTakesValue(global, words[i]) {
+				i++
+			}
+			continue
+		}
+		info, ok := c.cmdToInfo[words[i]]
+		if !ok || info.hidden {
+			return nil
+		}
+		return completeArgs(s, info.flagSet(), words[i+1:], info)
+	}
+
+	return completeArgs(s, global, words, commandInfo{
+		maxArgs:      1,
+		completeArgs: []completer{c.completeCommandNames},
+		completeFlags: map[string]completer{
+			"output": staticCompleter(outputFormats...),
+			"o":      staticCompleter(outputFormats...),
+		},
+	})
+}
+
+// completeArgs completes the last of words as a flag, a flag value or a
+// positional argument of info, depending on what precedes it.
+func completeArgs(s *state, fs *flag.FlagSet, words []string, info commandInfo) []candidate {
+	current := words[len(words)-1]
+	if len(words) > 1 {
+		prev := words[len(words)-2]
+		if strings.HasPrefix(prev, "-") && flagTakesValue(fs, prev) {
+			return filterCandidates(s, info.completeFlags[flagName(prev)], current)
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var candidates []candidate
+		fs.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, candidate{value: "--" + f.Name, description: f.Usage})
+		})
+		return filterCandidates(s, func(*state) ([]candidate, error) { return candidates, nil }, current)
+	}
+	return filterCandidates(s, info.argCompleter(argPosition(fs, words[:len(words)-1])), current)
+}
+
+// argPosition returns the position among the positional arguments of the
+// word following words, skipping flags and their values.
+func argPosition(fs *flag.FlagSet, words []string) int {
+	pos := 0
+	for i := 0; i < len(words); i++ {
+		switch {
+		case words[i] == "--":
+			return pos + len(words) - i - 1
+		case strings.HasPrefix(words[i], "-") && words[i] != "-":
+			if flagTakesValue(fs, words[i]) {
+				i++
+			}
+		default:
+			pos++
+		}
+	}
+	return pos
+}
+
+// argCompleter returns the completer for the positional argument at pos.
+// Commands taking any number of arguments complete the ones past the end
+// of completeArgs with its last completer.
+func (info commandInfo) argCompleter(pos int) completer {
+	if pos < len(info.completeArgs) {
+		return info.completeArgs[pos]
+	}
+	if info.maxArgs == unlimitedArgs && len(info.completeArgs) > 0 {
+		return info.completeArgs[len(info.completeArgs)-1]
+	}
+	return nil
+}
+
+func filterCandidates(s *state, complete completer, prefix string) []candidate {
+	if complete == nil {
+		return nil
+	}
+	candidates, err := complete(s)
+	if err != nil {
+		return nil
+	}
+	var matches []candidate
+	for _, cand := range candidates {
+		if strings.HasPrefix(cand.value, prefix) {
+			matches = append(matches, cand)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].value < matches[j].value })
+	return matches
+}
+
+func flagName(word string) string {
+	name, _, _ := strings.Cut(strings.TrimLeft(word, "-"), "=")
+	return name
+}
+
+// flagTakesValue reports whether word is a flag in fs whose value is the
+// next word, as opposed to a boolean flag or one written as --name=value.
+func flagTakesValue(fs *flag.FlagSet, word string) bool {
+	if word == "--" || strings.Contains(word, "=") {
+		return false
+	}
+	f := fs.Lookup(flagName(word))
+	if f == nil {
+		return false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return false
+	}
+	return true
+}
+
+func handleCompletion(_ *state, cmd command) error {
+	script, ok := completionScripts[cmd.args[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q: must be bash, zsh or fish", cmd.args[0])
+	}
+	fmt.Print(script)
+	return nil
+}
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+const bashCompletion = `# bash completion for gator
+# Load it with: source <(gator completion bash)
+
+_gator() {
+    local cur words cword
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        _get_comp_words_by_ref -n =: cur words cword
+    else
+        cur="${COMP_WORDS[COMP_CWORD]}"
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+    fi
+
+    local IFS=$'\n'
+    COMPREPLY=($(compgen -W "$(gator __complete "${words[@]:1:cword}" 2>/dev/null | cut -f1)" -- "$cur"))
+    if declare -F __ltrim_colon_completions >/dev/null; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+
+complete -F _gator gator
+`
+
+const zshCompletion = `#compdef gator
+# zsh completion for gator
+# Load it with: source <(gator completion zsh)
+
+_gator() {
+    local -a candidates
+    local line value desc
+    for line in "${(@f)$(gator __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -n "$line" ]] || continue
+        value="${line%%$'\t'*}"
+        desc=""
+        [[ "$line" == *$'\t'* ]] && desc="${line#*$'\t'}"
+        candidates+=("${value//:/\\:}${desc:+:$desc}")
+    done
+    _describe 'gator' candidates
+}
+
+if [[ "$funcstack[1]" == "_gator" ]]; then
+    _gator "$@"
+else
+    compdef _gator gator
+fi
+`
+
+const fishCompletion = `# fish completion for gator
+# Load it with: gator completion fish | source
+
+function __gator_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    gator __complete $tokens[2..-1] 2>/dev/null
+end
+
+complete -c gator -f -a '(__gator_complete)'
+`
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestArgPosition(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  int
+	}{
+		{words: nil, want: 0},
+		{words: []string{"a"}, want: 1},
+		{words: []string{"--yes", "a"}, want: 1},
+		{words: []string{"--feed", "x", "a"}, want: 1},
+		{words: []string{"--feed=x", "a", "b"}, want: 2},
+		{words: []string{"a", "--feed"}, want: 1},
+		{words: []string{"-", "a"}, want: 2},
+		{words: []string{"a", "--", "--yes", "b"}, want: 3},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String("feed", "", "")
+		fs.Bool("yes", false, "")
+		if got := argPosition(fs, tt.words); got != tt.want {
+			t.Errorf("argPosition(%q) = %d, want %d", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestArgCompleter(t *testing.T) {
+	first := staticCompleter("first")
+	second := staticCompleter("second")
+	tests := []struct {
+		name    string
+		maxArgs int
+		pos     int
+		want    string
+	}{
+		{name: "first", maxArgs: 2, pos: 0, want: "first"},
+		{name: "second", maxArgs: 2, pos: 1, want: "second"},
+		{name: "past the end", maxArgs: 3, pos: 2, want: ""},
+		{name: "past the end of unlimited", maxArgs: unlimitedArgs, pos: 5, want: "second"},
+	}
+	for _, tt := range tests {
+		info := commandInfo{maxArgs: tt.maxArgs, completeArgs: []completer{first, second}}
+		complete := info.argCompleter(tt.pos)
+		got := ""
+		if complete != nil {
+			candidates, err := complete(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = candidates[0].value
+		}
+		if got != tt.want {
+			t.Errorf("%s: argCompleter(%d) completes %q, want %q", tt.name, tt.pos, got, tt.want)
+		}
+	}
+}
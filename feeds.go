@@ -9,6 +9,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/awbalessa/gator/internal/database"
@@ -25,10 +26,13 @@ type RSSFeed struct {
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
 }
 
 func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
@@ -54,30 +58,44 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 		return nil, fmt.Errorf("error unmarshalling: %v", err)
 	}
 
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
+	feed.Channel.Title = stripControl(html.UnescapeString(feed.Channel.Title))
 	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
 
 	for i := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
+		feed.Channel.Item[i].Title = stripControl(html.UnescapeString(feed.Channel.Item[i].Title))
 		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+		feed.Channel.Item[i].Author = stripControl(html.UnescapeString(feed.Channel.Item[i].Author))
+		feed.Channel.Item[i].Creator = stripControl(html.UnescapeString(feed.Channel.Item[i].Creator))
 	}
 
 	return &feed, nil
 }
 
-func scrapeFeeds(s *state) error {
+func scrapeFeeds(s *state) (scrapeView, error) {
 	nextFeed, err := s.db.GetNextFeedToFetch(context.Background())
 	if err != nil {
-		return fmt.Errorf("error getting next feed: %v", err)
+		return scrapeView{}, fmt.Errorf("error getting next feed: %v", err)
 	}
 
 	rssFeed, err := fetchFeed(context.Background(), nextFeed.Url)
 	if err != nil {
-		return fmt.Errorf("error fetching RSS feed via URL: %v", err)
+		return scrapeView{}, fmt.Errorf("error fetching RSS feed via URL: %v", err)
 	}
 
 	if err = s.db.MarkFeedFetched(context.Background(), nextFeed.ID); err != nil {
-		return fmt.Errorf("error marking as fetched: %v", err)
+		return scrapeView{}, fmt.Errorf("error marking as fetched: %v", err)
+	}
+
+	result := scrapeView{
+		FeedID:    nextFeed.ID,
+		FeedName:  nextFeed.Name,
+		FeedUrl:   nextFeed.Url,
+		FetchedAt: time.Now(),
+	}
+
+	rules, err := feedFilterRules(context.Background(), s.db, nextFeed.ID)
+	if err != nil {
+		log.Printf("error getting filter rules: %v", err)
 	}
 
 	for i := range rssFeed.Channel.Item {
@@ -88,6 +106,12 @@ func scrapeFeeds(s *state) error {
 			log.Printf("error checking for existing post: %v", err)
 			continue
 		}
+		if pruned, err := s.db.MarkPrunedPostSeen(context.Background(), rssFeed.Channel.Item[i].Link); err != nil {
+			log.Printf("error checking for pruned post: %v", err)
+			continue
+		} else if pruned > 0 {
+			continue
+		}
 		pub, err := parsePublishedDate(rssFeed.Channel.Item[i].PubDate)
 		if err != nil {
 			log.Printf("error parsing duration: %v", err)
@@ -102,16 +126,28 @@ func scrapeFeeds(s *state) error {
 			Description: rssFeed.Channel.Item[i].Description,
 			PublishedAt: pub,
 			FeedID:      nextFeed.ID,
+			ContentText: renderHTML(rssFeed.Channel.Item[i].Description, renderOptions{}),
+			Author:      itemAuthor(rssFeed.Channel.Item[i]),
 		}
 
-		_, err = s.db.CreatePost(context.Background(), postParams)
+		post, err := s.db.CreatePost(context.Background(), postParams)
 		if err != nil {
 			log.Printf("error creating post: %v", err)
 			continue
 		}
-		fmt.Printf("Created post %d successfully\n", i+1)
+		result.NewPosts++
+		for _, category := range itemCategories(rssFeed.Channel.Item[i]) {
+			err = s.db.AddPostCategory(context.Background(), database.AddPostCategoryParams{
+				PostID:   post.ID,
+				Category: category,
+			})
+			if err != nil {
+				log.Printf("error adding category to post: %v", err)
+			}
+		}
+		applyFilterRules(context.Background(), s.db, rules, post)
 	}
-	return nil
+	return result, nil
 }
 
 func parsePublishedDate(dateStr string) (time.Time, error) {
@@ -137,3 +173,28 @@ func parsePublishedDate(dateStr string) (time.Time, error) {
 	// If all parsing attempts fail
 	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
 }
+
+// itemCategories returns the item's categories, trimmed and without
+// duplicates.
+func itemCategories(item RSSItem) []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, category := range item.Categories {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[strings.ToLower(category)] {
+			continue
+		}
+		seen[strings.ToLower(category)] = true
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// itemAuthor returns the item's <dc:creator>, or its RSS <author>, which is
+// usually an email address.
+func itemAuthor(item RSSItem) string {
+	if author := strings.TrimSpace(item.Creator); author != "" {
+		return author
+	}
+	return strings.TrimSpace(item.Author)
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// The Fever API lets existing reader apps (Reeder, ReadKit, Unread, ...)
+// sync against gator. Clients sign in with a gator username as the email and
+// the password set with "gator feverpass", and identify themselves with
+// api_key = md5("<username>:<password>") on every request. Fever uses
+// integer IDs, so feeds and posts are identified by their short_id columns.
+// Groups are the user's folders, numbered in the order "gator following"
+// lists them. Fever groups don't nest, so a subfolder is its own group named
+// by its full path, and feeds at the top level are in no group.
+
+const (
+	feverAPIVersion = 3
+	feverItemLimit  = 50
+)
+
+type feverGroup struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type feverFeedsGroup struct {
+	GroupID int64  `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feverFeed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	Url               string `json:"url"`
+	SiteUrl           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+type feverItem struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	Html          string `json:"html"`
+	Url           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+func feverAPIKey(username, password string) string {
+	sum := md5.Sum([]byte(username + ":" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// handleFeverPass prompts for the password instead of taking it as an
+// argument, which would leave it in the shell history and process list.
+func handleFeverPass(s *state, _ command, user database.User) error {
+	password, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+	err = s.db.SetFeverAPIKey(context.Background(), database.SetFeverAPIKeyParams{
+		UserID:    user.ID,
+		ApiKey:    feverAPIKey(user.Name, password),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error setting Fever password: %v", err)
+	}
+	msg := fmt.Sprintf("Fever password set, sign in with email %q", user.Name)
+	return s.out.print(messageView{Message: msg}, func() {
+		fmt.Println(msg)
+	})
+}
+
+// handleFever answers a Fever API request. Each request may ask for several
+// things at once, so the response is assembled from the parameters present.
+func (srv *server) handleFever(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+	resp := map[string]any{
+		"api_version": feverAPIVersion,
+		"auth":        0,
+	}
+	user, err := srv.s.db.GetUserByFeverAPIKey(r.Context(), strings.ToLower(r.Form.Get("api_key")))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondJSON(w, http.StatusOK, resp)
+		return
+	} else if err != nil {
+		respondInternalError(w, "error getting user", err)
+		return
+	}
+	resp["auth"] = 1
+
+	if err = srv.feverMark(r.Context(), r.Form, user); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	follows, err := srv.s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondInternalError(w, "error getting feed follows for user", err)
+		return
+	}
+	feeds, err := srv.feverFeeds(r.Context(), follows)
+	if err != nil {
+		respondInternalError(w, "error getting feeds", err)
+		return
+	}
+	var lastRefreshed int64
+	for _, feed := range feeds {
+		lastRefreshed = max(lastRefreshed, feed.LastUpdatedOnTime)
+	}
+	resp["last_refreshed_on_time"] = lastRefreshed
+
+	groups, feedsGroups := feverGroups(follows)
+	if r.Form.Has("groups") || r.Form.Has("feeds") {
+		resp["feeds_groups"] = feedsGroups
+	}
+	if r.Form.Has("groups") {
+		resp["groups"] = groups
+	}
+	if r.Form.Has("feeds") {
+		resp["feeds"] = feeds
+	}
+	if r.Form.Has("favicons") {
+		resp["favicons"] = []struct{}{}
+	}
+	if r.Form.Has("links") {
+		resp["links"] = []struct{}{}
+	}
+	if r.Form.Has("items") {
+		items, err := srv.feverItems(r.Context(), r.Form, user)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		total, err := srv.s.db.CountPostsForUser(r.Context(), user.ID)
+		if err != nil {
+			respondInternalError(w, "error counting posts", err)
+			return
+		}
+		resp["items"] = items
+		resp["total_items"] = total
+	}
+	if r.Form.Has("unread_item_ids") || r.Form.Get("as") == "read" || r.Form.Get("as") == "unread" {
+		ids, err := srv.s.db.GetUnreadPostShortIDsForUser(r.Context(), user.ID)
+		if err != nil {
+			respondInternalError(w, "error getting unread posts", err)
+			return
+		}
+		resp["unread_item_ids"] = joinIDs(ids)
+	}
+	if r.Form.Has("saved_item_ids") || r.Form.Get("as") == "saved" || r.Form.Get("as") == "unsaved" {
+		ids, err := srv.s.db.GetStarredPostShortIDsForUser(r.Context(), user.ID)
+		if err != nil {
+			respondInternalError(w, "error getting starred posts", err)
+			return
+		}
+		resp["saved_item_ids"] = joinIDs(ids)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (srv *server) feverFeeds(ctx context.Context, follows []database.GetFeedFollowsForUserRow) ([]feverFeed, error) {
+	all, err := srv.s.db.GetFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastFetched := make(map[uuid.UUID]int64, len(all))
+	for _, feed := range all {
+		if feed.LastFetchedAt.Valid {
+			lastFetched[feed.ID] = feed.LastFetchedAt.Time.Unix()
+		}
+	}
+	feeds := make([]feverFeed, 0, len(follows))
+	for _, follow := range follows {
+		feeds = append(feeds, feverFeed{
+			ID:                follow.FeedShortID,
+			Title:             follow.FeedName,
+			Url:               follow.FeedUrl,
+			SiteUrl:           follow.FeedUrl,
+			LastUpdatedOnTime: lastFetched[follow.FeedID],
+		})
+	}
+	return feeds, nil
+}
+
+// feverGroups returns a group for each folder the user has put feeds in,
+// along with the feeds in each group.
+func feverGroups(follows []database.GetFeedFollowsForUserRow) ([]feverGroup, []feverFeedsGroup) {
+	groups := []feverGroup{}
+	feedsGroups := []feverFeedsGroup{}
+	var ids []int64
+	for i, follow := range follows {
+		if follow.Folder == "" {
+			continue
+		}
+		ids = append(ids, follow.FeedShortID)
+		if i+1 < len(follows) && follows[i+1].Folder == follow.Folder {
+			continue
+		}
+		id := int64(len(groups) + 1)
+		groups = append(groups, feverGroup{ID: id, Title: follow.Folder})
+		feedsGroups = append(feedsGroups, feverFeedsGroup{GroupID: id, FeedIDs: joinIDs(ids)})
+		ids = nil
+	}
+	return groups, feedsGroups
+}
+
+// feverItems returns up to 50 posts: those after since_id in ascending
+// order, those before max_id in descending order, or those in with_ids.
+func (srv *server) feverItems(ctx context.Context, form url.Values, user database.User) ([]feverItem, error) {
+	params := database.GetPostsForUserByShortIDParams{
+		UserID: user.ID,
+		Limit:  feverItemLimit,
+	}
+	var err error
+	if params.SinceID, err = formNullInt(form, "since_id"); err != nil {
+		return nil, err
+	}
+	if params.MaxID, err = formNullInt(form, "max_id"); err != nil {
+		return nil, err
+	}
+	if form.Has("with_ids") {
+		params.Ids = []int64{}
+		for _, field := range strings.Split(form.Get("with_ids"), ",") {
+			if field = strings.TrimSpace(field); field == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid item ID %q", field)
+			}
+			params.Ids = append(params.Ids, id)
+		}
+	}
+
+	rows, err := srv.s.db.GetPostsForUserByShortID(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]feverItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, feverItem{
+			ID:            row.ShortID,
+			FeedID:        row.FeedShortID,
+			Title:         row.Title,
+			Author:        row.Author,
+			Html:          row.Description,
+			Url:           row.Url,
+			IsSaved:       boolInt(row.IsStarred),
+			IsRead:        boolInt(row.IsRead),
+			CreatedOnTime: row.PublishedAt.Unix(),
+		})
+	}
+	return items, nil
+}
+
+// feverMark applies a mark=item|feed|group request, if there is one.
+func (srv *server) feverMark(ctx context.Context, form url.Values, user database.User) error {
+	mark, as := form.Get("mark"), form.Get("as")
+	if mark == "" {
+		return nil
+	}
+	id, err := strconv.ParseInt(form.Get("id"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid id %q", form.Get("id"))
+	}
+
+	switch mark {
+	case "item":
+		post, err := srv.s.db.GetPostByShortID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error getting item %d: %v", id, err)
+		}
+		switch as {
+		case "read":
+			return srv.s.db.MarkPostRead(ctx, database.MarkPostReadParams{UserID: user.ID, PostID: post.ID, ReadAt: time.Now()})
+		case "unread":
+			return srv.s.db.MarkPostUnread(ctx, database.MarkPostUnreadParams{UserID: user.ID, PostID: post.ID})
+		case "saved":
+			return srv.s.db.StarPost(ctx, database.StarPostParams{UserID: user.ID, PostID: post.ID, StarredAt: time.Now()})
+		case "unsaved":
+			return srv.s.db.UnstarPost(ctx, database.UnstarPostParams{UserID: user.ID, PostID: post.ID})
+		}
+		return fmt.Errorf("cannot mark an item as %q", as)
+	case "feed", "group":
+		if as != "read" {
+			return fmt.Errorf("cannot mark a %s as %q", mark, as)
+		}
+		before := time.Now()
+		if seconds, err := strconv.ParseInt(form.Get("before"), 10, 64); err == nil && seconds > 0 {
+			before = time.Unix(seconds, 0)
+		}
+		if mark == "group" && id == 0 {
+			// Group 0 is Fever's "Kindling" group holding every feed.
+			_, err = srv.s.db.MarkAllReadBefore(ctx, database.MarkAllReadBeforeParams{
+				UserID: user.ID,
+				ReadAt: time.Now(),
+				Before: before,
+			})
+			return err
+		}
+		follows, err := srv.s.db.GetFeedFollowsForUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		feedIDs := map[int64]bool{id: true}
+		if mark == "group" {
+			_, feedsGroups := feverGroups(follows)
+			if id < 1 || id > int64(len(feedsGroups)) {
+				return fmt.Errorf("unknown group %d", id)
+			}
+			feedIDs = make(map[int64]bool)
+			for _, field := range strings.Split(feedsGroups[id-1].FeedIDs, ",") {
+				feedID, _ := strconv.ParseInt(field, 10, 64)
+				feedIDs[feedID] = true
+			}
+		}
+		marked := false
+		for _, follow := range follows {
+			if !feedIDs[follow.FeedShortID] {
+				continue
+			}
+			_, err = srv.s.db.MarkFeedReadBefore(ctx, database.MarkFeedReadBeforeParams{
+				UserID: user.ID,
+				ReadAt: time.Now(),
+				FeedID: follow.FeedID,
+				Before: before,
+			})
+			if err != nil {
+				return err
+			}
+			marked = true
+		}
+		if !marked && mark == "feed" {
+			return fmt.Errorf("feed %d is not followed", id)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown mark %q", mark)
+}
+
+func formNullInt(form url.Values, key string) (sql.NullInt64, error) {
+	if form.Get(key) == "" {
+		return sql.NullInt64{}, nil
+	}
+	n, err := strconv.ParseInt(form.Get(key), 10, 64)
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("invalid %s %q", key, form.Get(key))
+	}
+	return sql.NullInt64{Int64: n, Valid: true}, nil
+}
+
+func joinIDs(ids []int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
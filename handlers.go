@@ -4,79 +4,123 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/awbalessa/gator/internal/config"
 	"github.com/awbalessa/gator/internal/database"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// uniqueViolation is the Postgres error code for a broken unique constraint.
+const uniqueViolation = "23505"
+
+// foreignKeyViolation is the Postgres error code for a reference to a row
+// that doesn't exist.
+const foreignKeyViolation = "23503"
+
 type state struct {
 	cfg *config.Config
 	db  *database.Queries
+	// conn is the connection pool behind db, for starting transactions.
+	conn *sql.DB
+	out  *printer
+	// opts are the global flags out was built from.
+	opts    globalOptions
+	inShell bool
 }
 
-type command struct {
-	name string
-	args []string
-}
-
+// handleLogin checks the user's password and starts a session. Users created
+// before gator had passwords are locked until an admin sets one for them.
 func handleLogin(s *state, cmd command) error {
-	if len(cmd.args) == 0 {
-		return fmt.Errorf("username required")
-	}
-
-	if _, err := s.db.GetUser(context.Background(), cmd.args[0]); errors.Is(err, sql.ErrNoRows) {
+	user, err := s.db.GetUser(context.Background(), cmd.args[0])
+	if errors.Is(err, sql.ErrNoRows) {
 		return fmt.Errorf("user does not exist: %v", err)
 	} else if err != nil {
 		return fmt.Errorf("error getting user: %v", err)
 	}
 
-	err := s.cfg.SetUser(cmd.args[0])
+	if user.HashedPassword == "" {
+		return fmt.Errorf("%s is locked until an admin sets a password with \"gator passwd %s\"", user.Name, user.Name)
+	}
+	password, err := readPassword("Password: ")
 	if err != nil {
 		return err
 	}
+	if !checkPassword(user, password) {
+		return fmt.Errorf("incorrect password")
+	}
 
-	fmt.Printf("User has been set to %s\n", s.cfg.GetUser())
-	return nil
+	if err = startSession(s, user); err != nil {
+		return err
+	}
+
+	return s.out.print(newUserView(user, s.cfg.GetUser()), func() {
+		fmt.Printf("User has been set to %s\n", s.cfg.GetUser())
+	})
 }
 
 func handleRegister(s *state, cmd command) error {
-	if len(cmd.args) == 0 {
-		return fmt.Errorf("username required")
-	}
 	_, err := s.db.GetUser(context.Background(), cmd.args[0])
 	if err == nil {
 		return fmt.Errorf("User %s already registered\n", cmd.args[0])
 	} else if errors.Is(err, sql.ErrNoRows) {
+		password, err := readNewPassword()
+		if err != nil {
+			return err
+		}
+		hash, err := hashPassword(password)
+		if err != nil {
+			return err
+		}
+		role, err := newUserRole(context.Background(), s.db)
+		if err != nil {
+			return err
+		}
 		userParams := database.CreateUserParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			Name:      cmd.args[0],
+			ID:             uuid.New(),
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+			Name:           cmd.args[0],
+			HashedPassword: hash,
+			Role:           role,
 		}
 		newUser, err := s.db.CreateUser(context.Background(), userParams)
 		if err != nil {
 			return fmt.Errorf("error creating user: %w", err)
 		}
-		s.cfg.SetUser(newUser.Name)
-		fmt.Printf("User has been created:\n %+v\n", newUser)
-		return nil
+		if err = startSession(s, newUser); err != nil {
+			return err
+		}
+		view := newUserView(newUser, s.cfg.GetUser())
+		return s.out.print(view, func() {
+			fmt.Printf("User has been created:\n %+v\n", view)
+		})
 	} else {
 		return fmt.Errorf("error occurred: %v", err)
 	}
 }
 
-func handleReset(s *state, _ command) error {
+func handleReset(s *state, cmd command, _ database.User) error {
+	if err := confirmDestructive(s, cmd, "delete every user, feed and post"); err != nil {
+		return err
+	}
 	err := s.db.Reset(context.Background())
 	if err != nil {
 		return fmt.Errorf("error resetting database: %v", err)
 	}
-	s.cfg.SetUser("")
-	fmt.Println("Database reset successfully")
-	return nil
+	if err = s.cfg.ClearSession(); err != nil {
+		return err
+	}
+	return s.out.print(messageView{Message: "Database reset successfully"}, func() {
+		fmt.Println("Database reset successfully")
+	})
 }
 
 func handleUsers(s *state, _ command) error {
@@ -84,51 +128,198 @@ func handleUsers(s *state, _ command) error {
 	if err != nil {
 		return fmt.Errorf("error getting users: %v", err)
 	}
+	views := make([]userView, 0, len(users))
 	for _, user := range users {
-		if user.Name == s.cfg.CurrentUsername {
-			fmt.Printf("* %s (current)\n", user.Name)
-		} else {
-			fmt.Printf("* %s\n", user.Name)
+		views = append(views, newUserView(user, s.cfg.GetUser()))
+	}
+	return s.out.print(views, func() {
+		for _, user := range views {
+			var notes []string
+			if user.Role == roleAdmin {
+				notes = append(notes, roleAdmin)
+			}
+			if user.Current {
+				notes = append(notes, "current")
+			}
+			if len(notes) > 0 {
+				fmt.Printf("* %s (%s)\n", user.Name, strings.Join(notes, ", "))
+			} else {
+				fmt.Printf("* %s\n", user.Name)
+			}
+		}
+	})
+}
+
+func setDelUserFlags(fs *flag.FlagSet) {
+	setDestructiveFlags(fs)
+	fs.String("transfer-to", "", "user to give the feeds they added to")
+}
+
+// handleDelUser deletes a user with their follows, read state and stars.
+// Feeds they added stay for everyone following them, owned by the user
+// given with --transfer-to or by nobody.
+func handleDelUser(s *state, cmd command, admin database.User) error {
+	user, err := s.db.GetUser(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting user: %v", err)
+	}
+	if user.ID == admin.ID {
+		return fmt.Errorf("you cannot delete yourself, ask another admin")
+	}
+	var heir database.User
+	if name := cmd.flagString("transfer-to"); name != "" {
+		if heir, err = s.db.GetUser(context.Background(), name); err != nil {
+			return fmt.Errorf("error getting user %s: %v", name, err)
+		}
+		if heir.ID == user.ID {
+			return fmt.Errorf("cannot transfer feeds to the user being deleted")
 		}
 	}
-	return nil
+	if err = confirmDestructive(s, cmd, fmt.Sprintf("delete %s with their follows, read state and stars", user.Name)); err != nil {
+		return err
+	}
+
+	transferred, err := deleteUser(context.Background(), s, user, heir)
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("Deleted %s", user.Name)
+	if heir.Name != "" {
+		msg += fmt.Sprintf(", %d %s transferred to %s", transferred, pluralFeeds(transferred), heir.Name)
+	}
+	return s.out.print(messageView{Message: msg}, func() {
+		fmt.Println(msg)
+	})
 }
 
-func handleAgg(s *state, cmd command) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("time between requests required")
+// deleteUser deletes user, first giving the feeds they added to heir unless
+// heir is the zero User. It returns how many feeds were transferred.
+func deleteUser(ctx context.Context, s *state, user, heir database.User) (int64, error) {
+	// Transfer and delete together, so that a failed delete doesn't leave the
+	// feeds transferred and a failed transfer doesn't orphan them.
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	var transferred int64
+	if heir.Name != "" {
+		transferred, err = qtx.TransferFeeds(ctx, database.TransferFeedsParams{
+			AddedBy:   uuid.NullUUID{UUID: user.ID, Valid: true},
+			AddedBy_2: uuid.NullUUID{UUID: heir.ID, Valid: true},
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("error transferring feeds: %v", err)
+		}
+	}
+	if _, err = qtx.DeleteUser(ctx, user.ID); err != nil {
+		return 0, fmt.Errorf("error deleting user: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error deleting user: %v", err)
+	}
+	return transferred, nil
+}
+
+func pluralFeeds(n int64) string {
+	if n == 1 {
+		return "feed"
+	}
+	return "feeds"
+}
+
+// handleRenameUser renames a user. Users may rename themselves; renaming
+// anyone else takes an admin.
+func handleRenameUser(s *state, cmd command, current database.User) error {
+	user, err := s.db.GetUser(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting user: %v", err)
+	}
+	if user.ID != current.ID && current.Role != roleAdmin {
+		return fmt.Errorf("only admins can rename other users")
+	}
+	if _, err = s.db.GetUser(context.Background(), cmd.args[1]); err == nil {
+		return fmt.Errorf("user %s already exists", cmd.args[1])
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error getting user: %v", err)
+	}
+
+	err = s.db.RenameUser(context.Background(), database.RenameUserParams{
+		ID:        user.ID,
+		Name:      cmd.args[1],
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error renaming user: %v", err)
+	}
+	if s.cfg.GetUser() == user.Name {
+		if err = s.cfg.SetSession(cmd.args[1], s.cfg.GetSessionToken()); err != nil {
+			return err
+		}
 	}
+	user.Name = cmd.args[1]
+	return s.out.print(newUserView(user, s.cfg.GetUser()), func() {
+		fmt.Printf("Renamed %s to %s\n", cmd.args[0], user.Name)
+		fmt.Println("Fever clients sign in with the user name, so run \"gator feverpass\" again if you use one.")
+		fmt.Println("Published feed URLs contain the user name too, so run \"gator share\" again for a new one.")
+	})
+}
 
+func handleAgg(s *state, cmd command) error {
 	duration, err := time.ParseDuration(cmd.args[0])
 	if err != nil {
 		return fmt.Errorf("error parsing duration %v", err)
 	}
 
-	fmt.Printf("Collecting feeds every %s\n", cmd.args[0])
+	// Pruning deletes posts for everyone, so like "gator prune" it needs an
+	// admin to be logged in.
+	pruneEvery := cmd.flagDuration("prune-every")
+	if pruneEvery > 0 {
+		user, err := currentUser(context.Background(), s)
+		if errors.Is(err, errNotLoggedIn) {
+			return fmt.Errorf("--prune-every requires a logged in admin")
+		} else if err != nil {
+			return err
+		}
+		if user.Role != roleAdmin {
+			return fmt.Errorf("--prune-every can only be used by an admin")
+		}
+	}
+	if s.out.isText() {
+		fmt.Printf("Collecting feeds every %s\n", cmd.args[0])
+	}
+	var lastPrune time.Time
 	ticker := time.NewTicker(duration)
 	for ; ; <-ticker.C {
-		if err = scrapeFeeds(s); err != nil {
-			fmt.Printf("error scraping feed %v\n", err)
+		if pruneEvery > 0 && time.Since(lastPrune) >= pruneEvery {
+			pruneBetweenCycles(s)
+			lastPrune = time.Now()
+		}
+		result, err := scrapeFeeds(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error scraping feed %v\n", err)
+			continue
+		}
+		err = s.out.print(result, func() {
+			fmt.Printf("Fetched %s: %d new posts\n", result.FeedName, result.NewPosts)
+		})
+		if err != nil {
+			return err
 		}
 	}
-	return nil
 }
 
 func handleAddFeed(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 2 {
-		return fmt.Errorf("feed name and url required")
-	}
-	if s.cfg.GetUser() == "" {
-		return fmt.Errorf("you must be logged in to add a feed")
-	}
-
 	feedParams := database.CreateFeedParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Name:      cmd.args[0],
 		Url:       cmd.args[1],
-		UserID:    user.ID,
+		AddedBy:   uuid.NullUUID{UUID: user.ID, Valid: true},
 	}
 
 	feed, err := s.db.CreateFeed(context.Background(), feedParams)
@@ -148,8 +339,9 @@ func handleAddFeed(s *state, cmd command, user database.User) error {
 		return fmt.Errorf("error creating feed follow record: %v", err)
 	}
 
-	fmt.Printf("Feed added & followed successfully:\n %+v\n", feed)
-	return nil
+	return s.out.print(newFeedView(feed, user.Name), func() {
+		fmt.Printf("Feed added & followed successfully:\n %+v\n", feed)
+	})
 }
 
 func handleFeeds(s *state, _ command) error {
@@ -158,21 +350,207 @@ func handleFeeds(s *state, _ command) error {
 		return fmt.Errorf("error getting feeds: %v", err)
 	}
 
+	views := make([]feedView, 0, len(feeds))
 	for i := range feeds {
-		user, err := s.db.GetUserByID(context.Background(), feeds[i].UserID)
+		owner, err := feedOwner(context.Background(), s.db, feeds[i])
 		if err != nil {
-			return fmt.Errorf("error getting user by ID %v", err)
+			return err
 		}
-		fmt.Printf("Feed #%d:\nName: %s\nURL: %s\nOwner: %s\n", i+1, feeds[i].Name, feeds[i].Url, user.Name)
+		views = append(views, newFeedView(feeds[i], owner))
 	}
-	return nil
+	return s.out.print(views, func() {
+		for i, feed := range views {
+			owner := feed.Owner
+			if owner == "" {
+				owner = "(none)"
+			}
+			fmt.Printf("Feed #%d:\nName: %s\nURL: %s\nOwner: %s\n", i+1, feed.Name, feed.Url, owner)
+		}
+	})
 }
 
-func handleFollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("url required")
+// handleRmFeed deletes a feed for everyone, with its follows and posts, after
+// showing what that affects and asking for confirmation. Owners who aren't
+// admins can't delete a feed while other users follow it or starred its
+// posts.
+func handleRmFeed(s *state, cmd command, user database.User) error {
+	feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting feed: %v", err)
+	}
+	if !canManageFeed(user, feed) {
+		return fmt.Errorf("only the feed's owner or an admin can delete it")
+	}
+	stats, err := s.db.GetFeedStats(context.Background(), database.GetFeedStatsParams{
+		FeedID: feed.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting feed stats: %v", err)
+	}
+	// Deleting the feed deletes its posts, and with them other users' stars.
+	if user.Role != roleAdmin && (stats.OtherFollowers > 0 || stats.OtherStars > 0) {
+		return fmt.Errorf("cannot delete %s while others use it: followed by %d other %s, %d of its posts starred by others; ask an admin",
+			feed.Name, stats.OtherFollowers, pluralUsers(stats.OtherFollowers), stats.OtherStars)
 	}
+	what := fmt.Sprintf("delete %s, unfollowing it for %d %s and deleting %d posts, %d of them starred by other users",
+		feed.Name, stats.Followers, pluralUsers(stats.Followers), stats.Posts, stats.OtherStars)
+	if err = confirmDestructive(s, cmd, what); err != nil {
+		return err
+	}
+
+	if _, err = s.db.DeleteFeed(context.Background(), feed.ID); err != nil {
+		return fmt.Errorf("error deleting feed: %v", err)
+	}
+	msg := fmt.Sprintf("Deleted %s: %d %s unfollowed, %d posts deleted",
+		feed.Name, stats.Followers, pluralUsers(stats.Followers), stats.Posts)
+	return s.out.print(messageView{Message: msg}, func() {
+		fmt.Println(msg)
+	})
+}
 
+func pluralUsers(n int64) string {
+	if n == 1 {
+		return "user"
+	}
+	return "users"
+}
+
+// handleEditFeed changes a feed's name or URL.
+func handleEditFeed(s *state, cmd command, user database.User) error {
+	if !cmd.flagPassed("name") && !cmd.flagPassed("url") {
+		return fmt.Errorf("nothing to change, give --name or --url")
+	}
+	feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting feed: %v", err)
+	}
+	if !canManageFeed(user, feed) {
+		return fmt.Errorf("only the feed's owner or an admin can edit it")
+	}
+
+	params := database.UpdateFeedParams{
+		ID:        feed.ID,
+		Name:      feed.Name,
+		Url:       feed.Url,
+		UpdatedAt: time.Now(),
+	}
+	if cmd.flagPassed("name") {
+		if params.Name = strings.TrimSpace(cmd.flagString("name")); params.Name == "" {
+			return fmt.Errorf("name cannot be empty")
+		}
+	}
+	if cmd.flagPassed("url") {
+		params.Url = strings.TrimSpace(cmd.flagString("url"))
+		if u, err := url.Parse(params.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid feed URL %q", params.Url)
+		}
+		if params.Url != feed.Url {
+			if other, err := s.db.GetFeedByURL(context.Background(), params.Url); err == nil {
+				return fmt.Errorf("feed %s already has URL %s", other.Name, params.Url)
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("error getting feed: %v", err)
+			}
+		}
+	}
+
+	updated, err := s.db.UpdateFeed(context.Background(), params)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("another feed already has URL %s", params.Url)
+	} else if err != nil {
+		return fmt.Errorf("error updating feed: %v", err)
+	}
+	owner, err := feedOwner(context.Background(), s.db, updated)
+	if err != nil {
+		return err
+	}
+	return s.out.print(newFeedView(updated, owner), func() {
+		fmt.Printf("Updated feed:\nName: %s\nURL: %s\n", updated.Name, updated.Url)
+	})
+}
+
+// handleGC deletes feeds nobody follows, along with their posts. Feeds with
+// starred posts are kept so stars outlive unfollowing.
+func handleGC(s *state, cmd command, _ database.User) error {
+	orphans, err := s.db.GetOrphanedFeeds(context.Background())
+	if err != nil {
+		return fmt.Errorf("error getting unfollowed feeds: %v", err)
+	}
+	view := gcView{DryRun: cmd.flagBool("dry-run"), Feeds: []gcFeedView{}}
+	ids := make([]uuid.UUID, 0, len(orphans))
+	for _, feed := range orphans {
+		ids = append(ids, feed.ID)
+		view.Feeds = append(view.Feeds, gcFeedView{ID: feed.ID, Name: feed.Name, Url: feed.Url, Posts: feed.PostCount})
+		view.Posts += feed.PostCount
+	}
+	if !view.DryRun && len(ids) > 0 {
+		if _, err = s.db.DeleteOrphanedFeeds(context.Background(), ids); err != nil {
+			return fmt.Errorf("error deleting unfollowed feeds: %v", err)
+		}
+	}
+
+	return s.out.print(view, func() {
+		verb := "Deleted"
+		if view.DryRun {
+			verb = "Would delete"
+		}
+		for _, feed := range view.Feeds {
+			fmt.Printf("* %s (%s): %d posts\n", feed.Name, feed.Url, feed.Posts)
+		}
+		fmt.Printf("%s %d unfollowed %s and %d posts\n", verb, len(view.Feeds), pluralFeeds(int64(len(view.Feeds))), view.Posts)
+	})
+}
+
+// feedOwner returns the name of the user who added feed, or "" once that
+// user has been deleted.
+func feedOwner(ctx context.Context, db *database.Queries, feed database.Feed) (string, error) {
+	if !feed.AddedBy.Valid {
+		return "", nil
+	}
+	user, err := db.GetUserByID(ctx, feed.AddedBy.UUID)
+	if err != nil {
+		return "", fmt.Errorf("error getting user by ID %v", err)
+	}
+	return user.Name, nil
+}
+
+// canManageFeed reports whether user may change feed: admins can change
+// any feed, other users only feeds they added.
+func canManageFeed(user database.User, feed database.Feed) bool {
+	return user.Role == roleAdmin || (feed.AddedBy.Valid && feed.AddedBy.UUID == user.ID)
+}
+
+// handleTransferFeed gives a feed to another user. Only the feed's owner or
+// an admin may do so.
+func handleTransferFeed(s *state, cmd command, user database.User) error {
+	feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting feed: %v", err)
+	}
+	if !canManageFeed(user, feed) {
+		return fmt.Errorf("only the feed's owner or an admin can transfer it")
+	}
+	owner, err := s.db.GetUser(context.Background(), cmd.args[1])
+	if err != nil {
+		return fmt.Errorf("error getting user: %v", err)
+	}
+
+	err = s.db.SetFeedOwner(context.Background(), database.SetFeedOwnerParams{
+		ID:        feed.ID,
+		AddedBy:   uuid.NullUUID{UUID: owner.ID, Valid: true},
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error transferring feed: %v", err)
+	}
+	feed.AddedBy = uuid.NullUUID{UUID: owner.ID, Valid: true}
+	return s.out.print(newFeedView(feed, owner.Name), func() {
+		fmt.Printf("%s now belongs to %s\n", feed.Name, owner.Name)
+	})
+}
+
+func handleFollow(s *state, cmd command, user database.User) error {
 	feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[0])
 	if err != nil {
 		return fmt.Errorf("error getting feed: %v", err)
@@ -191,9 +569,17 @@ func handleFollow(s *state, cmd command, user database.User) error {
 		return fmt.Errorf("error creating feed follow: %v", err)
 	}
 
-	fmt.Printf("Feed: %s\n", feedFollowRow.FeedName)
-	fmt.Printf("User: %s\n", feedFollowRow.UserName)
-	return nil
+	view := followView{
+		FeedID:     feedFollowRow.FeedID,
+		FeedName:   feedFollowRow.FeedName,
+		FeedUrl:    feed.Url,
+		User:       feedFollowRow.UserName,
+		FollowedAt: feedFollowRow.CreatedAt,
+	}
+	return s.out.print(view, func() {
+		fmt.Printf("Feed: %s\n", view.FeedName)
+		fmt.Printf("User: %s\n", view.User)
+	})
 }
 
 func handleFollowing(s *state, _ command, user database.User) error {
@@ -202,20 +588,149 @@ func handleFollowing(s *state, _ command, user database.User) error {
 		return fmt.Errorf("error getting feed follows for user: %v", err)
 	}
 
-	for i := range feedFollows {
-		fmt.Println(feedFollows[i].FeedName)
+	views := make([]followView, 0, len(feedFollows))
+	for _, follow := range feedFollows {
+		views = append(views, newFollowView(follow))
 	}
-	return nil
+	// Saved searches are listed like feeds in text output; scripts get them
+	// from "gator searches" so that this output stays a list of follows.
+	var searches []savedSearchView
+	if s.out.isText() {
+		if searches, err = getSavedSearches(context.Background(), s.db, user); err != nil {
+			return err
+		}
+	}
+	return s.out.print(views, func() {
+		// Follows come sorted by folder, so each folder's heading is
+		// printed once, before the first feed in it or its subfolders.
+		var prev []string
+		for _, follow := range views {
+			parts := folderParts(follow.Folder)
+			common := 0
+			for common < len(prev) && common < len(parts) && prev[common] == parts[common] {
+				common++
+			}
+			for i := common; i < len(parts); i++ {
+				fmt.Printf("%s%s/\n", strings.Repeat("  ", i), parts[i])
+			}
+			fmt.Printf("%s%s (%d unread)\n", strings.Repeat("  ", len(parts)), follow.FeedName, follow.UnreadCount)
+			prev = parts
+		}
+		if len(searches) > 0 {
+			fmt.Println("Saved searches/")
+			for _, search := range searches {
+				fmt.Printf("  %s (%d unread)\n", search.Name, search.UnreadCount)
+			}
+		}
+	})
 }
 
-func handleUnfollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("feed URL required")
+// normalizeFolder cleans up a folder path such as " Tech / Go/", which
+// becomes "Tech/Go". The top level is "".
+func normalizeFolder(folder string) string {
+	return strings.Join(folderParts(folder), "/")
+}
+
+func folderParts(folder string) []string {
+	var parts []string
+	for _, part := range strings.Split(folder, "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
 	}
+	return parts
+}
 
+// handleEditFollow changes how the user sees a followed feed: its title,
+// folder and position within the folder.
+func handleEditFollow(s *state, cmd command, user database.User) error {
+	if !cmd.flagPassed("title") && !cmd.flagPassed("folder") && !cmd.flagPassed("position") {
+		return fmt.Errorf("nothing to change, give --title, --folder or --position")
+	}
 	feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[0])
 	if err != nil {
-		return fmt.Errorf("error getting feel: %v", err)
+		return fmt.Errorf("error getting feed: %v", err)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting feed follows for user: %v", err)
+	}
+	var follow *database.GetFeedFollowsForUserRow
+	for i := range follows {
+		if follows[i].FeedID == feed.ID {
+			follow = &follows[i]
+			break
+		}
+	}
+	if follow == nil {
+		return fmt.Errorf("you don't follow %s", feed.Url)
+	}
+
+	params := database.UpdateFeedFollowParams{
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+		Title:     follow.Title,
+		Folder:    follow.Folder,
+		Position:  follow.Position,
+		UpdatedAt: time.Now(),
+	}
+	if cmd.flagPassed("title") {
+		params.Title = strings.TrimSpace(cmd.flagString("title"))
+	}
+	if cmd.flagPassed("folder") {
+		params.Folder = normalizeFolder(cmd.flagString("folder"))
+	}
+	if cmd.flagPassed("position") {
+		params.Position = int32(cmd.flagInt("position"))
+	}
+	updated, err := s.db.UpdateFeedFollow(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error updating feed follow: %v", err)
+	}
+
+	follow.Title, follow.Folder, follow.Position = updated.Title, updated.Folder, updated.Position
+	follow.FeedName = feed.Name
+	if updated.Title != "" {
+		follow.FeedName = updated.Title
+	}
+	view := newFollowView(*follow)
+	return s.out.print(view, func() {
+		folder := view.Folder
+		if folder == "" {
+			folder = "(top level)"
+		}
+		fmt.Printf("Feed: %s\nFolder: %s\nPosition: %d\n", view.FeedName, folder, view.Position)
+	})
+}
+
+func completeFolders(s *state) ([]candidate, error) {
+	user, err := currentUser(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+	for _, follow := range follows {
+		parts := folderParts(follow.Folder)
+		for i := range parts {
+			folder := strings.Join(parts[:i+1], "/")
+			if !seen[folder] {
+				seen[folder] = true
+				candidates = append(candidates, candidate{value: folder})
+			}
+		}
+	}
+	return candidates, nil
+}
+
+func handleUnfollow(s *state, cmd command, user database.User) error {
+	feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting feed: %v", err)
 	}
 
 	deleteParams := database.DeleteByPairParams{
@@ -227,65 +742,340 @@ func handleUnfollow(s *state, cmd command, user database.User) error {
 		return fmt.Errorf("error deleting by pair: %v", err)
 	}
 
-	fmt.Println("Feed unfollowed successfully")
-	return nil
+	return s.out.print(messageView{Message: "Feed unfollowed successfully"}, func() {
+		fmt.Println("Feed unfollowed successfully")
+	})
+}
+
+func setBrowseFlags(fs *flag.FlagSet) {
+	fs.Int("limit", 2, "maximum number of posts to show")
+	fs.Int("offset", 0, "number of posts to skip, needs --unread=false")
+	fs.String("after", "", "cursor printed at the end of a previous page")
+	fs.String("feed", "", "only show posts from the feed with this URL")
+	fs.String("folder", "", "only show posts from feeds in this folder or its subfolders")
+	fs.String("saved", "", "only show posts matching this saved search")
+	fs.String("since", "", "only show posts at or after this time (date, RFC 3339 or duration like 7d)")
+	fs.String("until", "", "only show posts before this time (date, RFC 3339 or duration like 7d)")
+	fs.Bool("unread", true, "only show unread posts")
+	fs.String("sort", sortPublished, "sort by \"published\" or \"fetched\" time, newest first")
 }
 
 func handleBrowse(s *state, cmd command, user database.User) error {
-	var limit int32
-	if len(cmd.args) == 0 {
-		limit = 2
-	} else {
-		limitStr := cmd.args[0]
-		limitInt, err := strconv.Atoi(limitStr)
+	limit := cmd.flagInt("limit")
+	if len(cmd.args) > 0 {
+		limitInt, err := strconv.Atoi(cmd.args[0])
 		if err != nil {
 			return fmt.Errorf("invalid number provided: %v", err)
 		}
-		limit = int32(limitInt)
+		limit = limitInt
 	}
-	postParams := database.GetPostsFromUserParams{
-		UserID: user.ID,
-		Limit:  limit,
+	offset := cmd.flagInt("offset")
+	if limit < 1 || offset < 0 {
+		return fmt.Errorf("limit must be positive and offset must not be negative")
+	}
+	// Shown posts are marked read, so with --unread the next page's offset
+	// would skip over posts nobody has seen.
+	if offset > 0 && cmd.flagBool("unread") {
+		return fmt.Errorf("--offset cannot be used with --unread, page with --after or pass --unread=false")
+	}
+
+	filter := postFilter{
+		unreadOnly: cmd.flagBool("unread"),
+		sortBy:     cmd.flagString("sort"),
+		limit:      int32(limit),
+		offset:     int32(offset),
+	}
+	if feedURL := cmd.flagString("feed"); feedURL != "" {
+		feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+		if err != nil {
+			return fmt.Errorf("error getting feed: %v", err)
+		}
+		filter.feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if folder := normalizeFolder(cmd.flagString("folder")); folder != "" {
+		filter.folder = sql.NullString{String: folder, Valid: true}
+	}
+	if name := cmd.flagString("saved"); name != "" {
+		if filter.feedID.Valid || filter.folder.Valid {
+			return fmt.Errorf("--saved cannot be combined with --feed or --folder")
+		}
+		err := applySavedSearch(context.Background(), s.db, user, name, &filter)
+		if errors.Is(err, errNoSavedSearch) {
+			return fmt.Errorf("no saved search named %q", name)
+		} else if err != nil {
+			return err
+		}
+	}
+	var err error
+	if filter.since, err = parseTimeFlag(cmd.flagString("since")); err != nil {
+		return err
+	}
+	if filter.until, err = parseTimeFlag(cmd.flagString("until")); err != nil {
+		return err
+	}
+	if after := cmd.flagString("after"); after != "" {
+		if filter.cursor, err = decodeCursor(after); err != nil {
+			return err
+		}
 	}
 
-	posts, err := s.db.GetPostsFromUser(context.Background(), postParams)
+	posts, err := queryPosts(context.Background(), s.db, user.ID, filter)
 	if err != nil {
-		return fmt.Errorf("error getting posts from user", err)
+		return fmt.Errorf("error getting posts from user: %v", err)
 	}
-	for i, post := range posts {
-		fmt.Printf("\n--- Post #%d ---\n", i+1)
-		fmt.Printf("Title: %s\n", post.Title)
-		fmt.Printf("Description: %s\n", post.Description)
-		fmt.Printf("URL: %s\n", post.Url)
-		fmt.Printf("Published: %s\n", post.PublishedAt.Format(time.RFC1123))
-		fmt.Printf("Feed ID: %s\n", post.FeedID)
+	err = s.out.print(posts, func() {
+		if len(posts) == 0 {
+			fmt.Println("No posts found")
+		}
+		opts := terminalRenderOptions()
+		for i, post := range posts {
+			fmt.Printf("\n--- Post #%d ---\n", i+1)
+			fmt.Printf("ID: %s\n", post.ID)
+			fmt.Printf("Title: %s\n", post.Title)
+			fmt.Printf("URL: %s\n", post.Url)
+			fmt.Printf("Published: %s\n", post.PublishedAt.Format(time.RFC1123))
+			fmt.Printf("Feed: %s\n", post.FeedName)
+			if content := renderHTML(post.Description, opts); content != "" {
+				fmt.Printf("\n%s\n", content)
+			}
+		}
+	})
+	if err != nil {
+		return err
 	}
 
+	for _, post := range posts {
+		readParams := database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			ReadAt: time.Now(),
+		}
+		if err = s.db.MarkPostRead(context.Background(), readParams); err != nil {
+			return fmt.Errorf("error marking post as read: %v", err)
+		}
+	}
+
+	if len(posts) == int(filter.limit) {
+		next := fmt.Sprintf("Next page: browse --after %s", nextCursor(posts, filter.sortBy))
+		if s.out.isText() {
+			fmt.Printf("\n%s\n", next)
+		} else {
+			fmt.Fprintln(os.Stderr, next)
+		}
+	}
 	return nil
 }
 
-func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
-	return func(s *state, cmd command) error {
-		user, err := s.db.GetUser(context.Background(), s.cfg.GetUser())
+func handleRead(s *state, cmd command, user database.User) error {
+	if cmd.args[0] == "all" {
+		if len(cmd.args) < 2 {
+			return fmt.Errorf("feed URL required")
+		}
+		feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[1])
+		if err != nil {
+			return fmt.Errorf("error getting feed: %v", err)
+		}
+		params := database.MarkFeedReadParams{
+			UserID: user.ID,
+			ReadAt: time.Now(),
+			FeedID: feed.ID,
+		}
+		n, err := s.db.MarkFeedRead(context.Background(), params)
+		if err != nil {
+			return fmt.Errorf("error marking feed as read: %v", err)
+		}
+		return s.out.print(countView{Action: "read", Count: n}, func() {
+			fmt.Printf("Marked %d posts in %s as read\n", n, feed.Name)
+		})
+	}
+
+	ids, err := parsePostIDs(cmd.args)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		params := database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: id,
+			ReadAt: time.Now(),
+		}
+		if err = s.db.MarkPostRead(context.Background(), params); err != nil {
+			return fmt.Errorf("error marking post %s as read: %v", id, err)
+		}
+	}
+	return s.out.print(countView{Action: "read", Count: int64(len(ids))}, func() {
+		fmt.Printf("Marked %d posts as read\n", len(ids))
+	})
+}
+
+func handleUnread(s *state, cmd command, user database.User) error {
+	if cmd.args[0] == "all" {
+		if len(cmd.args) < 2 {
+			return fmt.Errorf("feed URL required")
+		}
+		feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[1])
+		if err != nil {
+			return fmt.Errorf("error getting feed: %v", err)
+		}
+		params := database.MarkFeedUnreadParams{
+			UserID: user.ID,
+			FeedID: feed.ID,
+		}
+		n, err := s.db.MarkFeedUnread(context.Background(), params)
 		if err != nil {
-			return fmt.Errorf("error getting user: %v", err)
+			return fmt.Errorf("error marking feed as unread: %v", err)
+		}
+		return s.out.print(countView{Action: "unread", Count: n}, func() {
+			fmt.Printf("Marked %d posts in %s as unread\n", n, feed.Name)
+		})
+	}
+
+	ids, err := parsePostIDs(cmd.args)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		params := database.MarkPostUnreadParams{
+			UserID: user.ID,
+			PostID: id,
+		}
+		if err = s.db.MarkPostUnread(context.Background(), params); err != nil {
+			return fmt.Errorf("error marking post %s as unread: %v", id, err)
+		}
+	}
+	return s.out.print(countView{Action: "unread", Count: int64(len(ids))}, func() {
+		fmt.Printf("Marked %d posts as unread\n", len(ids))
+	})
+}
+
+func handleStar(s *state, cmd command, user database.User) error {
+	ids, err := parsePostIDs(cmd.args)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		params := database.StarPostParams{
+			UserID:    user.ID,
+			PostID:    id,
+			StarredAt: time.Now(),
+		}
+		if err = s.db.StarPost(context.Background(), params); err != nil {
+			return fmt.Errorf("error starring post %s: %v", id, err)
 		}
+	}
+	return s.out.print(countView{Action: "star", Count: int64(len(ids))}, func() {
+		fmt.Printf("Starred %d posts\n", len(ids))
+	})
+}
 
-		return handler(s, cmd, user)
+func handleUnstar(s *state, cmd command, user database.User) error {
+	ids, err := parsePostIDs(cmd.args)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		params := database.UnstarPostParams{
+			UserID: user.ID,
+			PostID: id,
+		}
+		if err = s.db.UnstarPost(context.Background(), params); err != nil {
+			return fmt.Errorf("error unstarring post %s: %v", id, err)
+		}
 	}
+	return s.out.print(countView{Action: "unstar", Count: int64(len(ids))}, func() {
+		fmt.Printf("Unstarred %d posts\n", len(ids))
+	})
 }
 
-type commands struct {
-	cmdToHandler map[string]func(*state, command) error
+func handleStarred(s *state, cmd command, user database.User) error {
+	var limit int32
+	if len(cmd.args) == 0 {
+		limit = 10
+	} else {
+		limitInt, err := strconv.Atoi(cmd.args[0])
+		if err != nil {
+			return fmt.Errorf("invalid number provided: %v", err)
+		}
+		if limitInt < 1 {
+			return fmt.Errorf("limit must be positive")
+		}
+		limit = int32(limitInt)
+	}
+	params := database.GetStarredPostsForUserParams{
+		UserID: user.ID,
+		Limit:  limit,
+	}
+
+	posts, err := s.db.GetStarredPostsForUser(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error getting starred posts: %v", err)
+	}
+	views := make([]starredPostView, 0, len(posts))
+	for _, post := range posts {
+		views = append(views, newStarredPostView(post))
+	}
+	return s.out.print(views, func() {
+		if len(views) == 0 {
+			fmt.Println("No starred posts")
+		}
+		for i, post := range views {
+			fmt.Printf("\n--- Starred #%d ---\n", i+1)
+			fmt.Printf("ID: %s\n", post.ID)
+			fmt.Printf("Title: %s\n", post.Title)
+			fmt.Printf("Feed: %s\n", post.FeedName)
+			fmt.Printf("URL: %s\n", post.Url)
+			fmt.Printf("Published: %s\n", post.PublishedAt.Format(time.RFC1123))
+			fmt.Printf("Starred: %s\n", post.StarredAt.Format(time.RFC1123))
+		}
+	})
 }
 
-func (c *commands) register(name string, f func(*state, command) error) {
-	c.cmdToHandler[name] = f
+func handleSearch(s *state, cmd command, user database.User) error {
+	if cmd.flagInt("limit") < 1 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	query, err := buildTSQuery(strings.Join(cmd.args, " "))
+	if err != nil {
+		return err
+	}
+	params := database.SearchPostsForUserParams{
+		Query:  query,
+		UserID: user.ID,
+		Limit:  int32(cmd.flagInt("limit")),
+	}
+
+	results, err := s.db.SearchPostsForUser(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error searching posts: %v", err)
+	}
+	views := make([]searchResultView, 0, len(results))
+	for _, result := range results {
+		views = append(views, newSearchResultView(result))
+	}
+	return s.out.print(views, func() {
+		if len(results) == 0 {
+			fmt.Println("No matching posts")
+		}
+		for i, result := range results {
+			fmt.Printf("\n--- Result #%d (rank %.3f) ---\n", i+1, result.Rank)
+			fmt.Printf("ID: %s\n", result.ID)
+			fmt.Printf("Title: %s\n", highlight(result.TitleHeadline))
+			fmt.Printf("Feed: %s\n", result.FeedName)
+			fmt.Printf("URL: %s\n", result.Url)
+			fmt.Printf("Published: %s\n", result.PublishedAt.Format(time.RFC1123))
+			fmt.Printf("Snippet: %s\n", highlight(result.DescriptionHeadline))
+		}
+	})
 }
-func (c *commands) run(s *state, cmd command) error {
-	handler, ok := c.cmdToHandler[cmd.name]
-	if !ok {
-		return fmt.Errorf("command not registered")
+
+func parsePostIDs(args []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(args))
+	for _, arg := range args {
+		id, err := uuid.Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid post ID %q: %v", arg, err)
+		}
+		ids = append(ids, id)
 	}
-	return handler(s, cmd)
+	return ids, nil
 }
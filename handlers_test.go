@@ -0,0 +1,31 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFolderParts(t *testing.T) {
+	tests := []struct {
+		folder string
+		want   []string
+		joined string
+	}{
+		{folder: "", want: nil, joined: ""},
+		{folder: "/", want: nil, joined: ""},
+		{folder: "Tech", want: []string{"Tech"}, joined: "Tech"},
+		{folder: "Tech/Go", want: []string{"Tech", "Go"}, joined: "Tech/Go"},
+		{folder: " Tech / Go/", want: []string{"Tech", "Go"}, joined: "Tech/Go"},
+		{folder: "//Tech///Go//", want: []string{"Tech", "Go"}, joined: "Tech/Go"},
+		{folder: "News/ /World", want: []string{"News", "World"}, joined: "News/World"},
+		{folder: "Open Source/Go Blog", want: []string{"Open Source", "Go Blog"}, joined: "Open Source/Go Blog"},
+	}
+	for _, tt := range tests {
+		if got := folderParts(tt.folder); !slices.Equal(got, tt.want) {
+			t.Errorf("folderParts(%q) = %q, want %q", tt.folder, got, tt.want)
+		}
+		if got := normalizeFolder(tt.folder); got != tt.joined {
+			t.Errorf("normalizeFolder(%q) = %q, want %q", tt.folder, got, tt.joined)
+		}
+	}
+}
@@ -9,9 +9,13 @@ import (
 
 const configFileName = ".gatorconfig.json"
 
+// Config is gator's config file. SessionToken authenticates the logged in
+// user; CurrentUsername only records who that is for display.
 type Config struct {
-	CurrentUsername string `json:"current_user_name"`
-	DatabaseURL     string `json:"db_url"`
+	CurrentUsername string            `json:"current_user_name"`
+	SessionToken    string            `json:"session_token,omitempty"`
+	DatabaseURL     string            `json:"db_url"`
+	Templates       map[string]string `json:"templates,omitempty"`
 }
 
 func Read() (*Config, error) {
@@ -31,18 +35,35 @@ func Read() (*Config, error) {
 	return &config, nil
 }
 
-func (c *Config) SetUser(user string) error {
+// SetSession records that user is logged in with the session token.
+func (c *Config) SetSession(user, token string) error {
 	c.CurrentUsername = user
+	c.SessionToken = token
 	if err := write(c); err != nil {
 		return err
 	}
 	return nil
 }
 
+// ClearSession logs the current user out.
+func (c *Config) ClearSession() error {
+	return c.SetSession("", "")
+}
+
 func (c *Config) GetUser() string {
 	return c.CurrentUsername
 }
 
+func (c *Config) GetSessionToken() string {
+	return c.SessionToken
+}
+
+// Template returns the output template saved under name, if any.
+func (c *Config) Template(name string) (string, bool) {
+	tmpl, ok := c.Templates[name]
+	return tmpl, ok
+}
+
 func getConfigFilePath() (string, error) {
 	homedir, err := os.UserHomeDir()
 	if err != nil {
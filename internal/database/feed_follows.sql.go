@@ -22,9 +22,9 @@ WITH inserted_feed_follow AS (
         $4,
         $5
     )
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    RETURNING id, created_at, updated_at, user_id, feed_id, title, folder, position
 )
-SELECT inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
+SELECT inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.title, inserted_feed_follow.folder, inserted_feed_follow.position,
 feeds.name AS feed_name,
 users.name AS user_name
 FROM inserted_feed_follow
@@ -46,6 +46,9 @@ type CreateFeedFollowRow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Title     string
+	Folder    string
+	Position  int32
 	FeedName  string
 	UserName  string
 }
@@ -65,6 +68,9 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.Title,
+		&i.Folder,
+		&i.Position,
 		&i.FeedName,
 		&i.UserName,
 	)
@@ -74,7 +80,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 const deleteByPair = `-- name: DeleteByPair :exec
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2
-RETURNING id, created_at, updated_at, user_id, feed_id
+RETURNING id, created_at, updated_at, user_id, feed_id, title, folder, position
 `
 
 type DeleteByPairParams struct {
@@ -89,23 +95,46 @@ func (q *Queries) DeleteByPair(ctx context.Context, arg DeleteByPairParams) erro
 
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT
-    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id,
-    feeds.name AS feed_name,
-    users.name AS user_name
+    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.title, feed_follows.folder, feed_follows.position,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    feeds.url AS feed_url,
+    feeds.short_id AS feed_short_id,
+    users.name AS user_name,
+    (
+        SELECT COUNT(*) FROM posts
+        WHERE posts.feed_id = feed_follows.feed_id
+        AND NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id
+            AND post_reads.user_id = feed_follows.user_id
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM post_hides
+            WHERE post_hides.post_id = posts.id
+            AND post_hides.user_id = feed_follows.user_id
+        )
+    ) AS unread_count
 FROM feed_follows
 INNER JOIN users ON users.id = feed_follows.user_id
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
 WHERE feed_follows.user_id = $1
+ORDER BY string_to_array(feed_follows.folder, '/'), feed_follows.position, feed_name
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	Title       string
+	Folder      string
+	Position    int32
+	FeedName    string
+	FeedUrl     string
+	FeedShortID int64
+	UserName    string
+	UnreadCount int64
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -123,8 +152,14 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.Title,
+			&i.Folder,
+			&i.Position,
 			&i.FeedName,
+			&i.FeedUrl,
+			&i.FeedShortID,
 			&i.UserName,
+			&i.UnreadCount,
 		); err != nil {
 			return nil, err
 		}
@@ -138,3 +173,82 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const isFollowingFeed = `-- name: IsFollowingFeed :one
+SELECT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE user_id = $1 AND feed_id = $2
+)
+`
+
+type IsFollowingFeedParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+}
+
+func (q *Queries) IsFollowingFeed(ctx context.Context, arg IsFollowingFeedParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isFollowingFeed, arg.UserID, arg.FeedID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isFollowingPost = `-- name: IsFollowingPost :one
+SELECT EXISTS (
+    SELECT 1 FROM posts
+    JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+    WHERE feed_follows.user_id = $1 AND posts.id = $2
+)
+`
+
+type IsFollowingPostParams struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+// Reports whether the user follows the feed the post is from.
+func (q *Queries) IsFollowingPost(ctx context.Context, arg IsFollowingPostParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isFollowingPost, arg.UserID, arg.ID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const updateFeedFollow = `-- name: UpdateFeedFollow :one
+UPDATE feed_follows
+SET title = $3, folder = $4, position = $5, updated_at = $6
+WHERE user_id = $1 AND feed_id = $2
+RETURNING id, created_at, updated_at, user_id, feed_id, title, folder, position
+`
+
+type UpdateFeedFollowParams struct {
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	Title     string
+	Folder    string
+	Position  int32
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateFeedFollow(ctx context.Context, arg UpdateFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedFollow,
+		arg.UserID,
+		arg.FeedID,
+		arg.Title,
+		arg.Folder,
+		arg.Position,
+		arg.UpdatedAt,
+	)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Title,
+		&i.Folder,
+		&i.Position,
+	)
+	return i, err
+}
@@ -7,13 +7,15 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, added_by)
 VALUES (
     $1,
     $2,
@@ -22,7 +24,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, added_by, last_fetched_at, short_id
 `
 
 type CreateFeedParams struct {
@@ -31,7 +33,7 @@ type CreateFeedParams struct {
 	UpdatedAt time.Time
 	Name      string
 	Url       string
-	UserID    uuid.UUID
+	AddedBy   uuid.NullUUID
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -41,7 +43,7 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.UpdatedAt,
 		arg.Name,
 		arg.Url,
-		arg.UserID,
+		arg.AddedBy,
 	)
 	var i Feed
 	err := row.Scan(
@@ -50,14 +52,50 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.UpdatedAt,
 		&i.Name,
 		&i.Url,
-		&i.UserID,
+		&i.AddedBy,
 		&i.LastFetchedAt,
+		&i.ShortID,
 	)
 	return i, err
 }
 
+const deleteFeed = `-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeed, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOrphanedFeeds = `-- name: DeleteOrphanedFeeds :execrows
+DELETE FROM feeds
+WHERE feeds.id = ANY($1::uuid[])
+AND NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM user_post_stars
+    INNER JOIN posts ON posts.id = user_post_stars.post_id
+    WHERE posts.feed_id = feeds.id
+)
+`
+
+func (q *Queries) DeleteOrphanedFeeds(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOrphanedFeeds, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, added_by, last_fetched_at, short_id FROM feeds WHERE url = $1
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -69,14 +107,56 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.UpdatedAt,
 		&i.Name,
 		&i.Url,
-		&i.UserID,
+		&i.AddedBy,
 		&i.LastFetchedAt,
+		&i.ShortID,
+	)
+	return i, err
+}
+
+const getFeedStats = `-- name: GetFeedStats :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS followers,
+    (
+        SELECT COUNT(*) FROM feed_follows
+        WHERE feed_follows.feed_id = $1 AND feed_follows.user_id <> $2
+    ) AS other_followers,
+    (SELECT COUNT(*) FROM posts WHERE posts.feed_id = $1) AS posts,
+    (
+        SELECT COUNT(*) FROM user_post_stars
+        JOIN posts ON posts.id = user_post_stars.post_id
+        WHERE posts.feed_id = $1 AND user_post_stars.user_id <> $2
+    ) AS other_stars
+`
+
+type GetFeedStatsParams struct {
+	FeedID uuid.UUID
+	UserID uuid.UUID
+}
+
+type GetFeedStatsRow struct {
+	Followers      int64
+	OtherFollowers int64
+	Posts          int64
+	OtherStars     int64
+}
+
+// Counts what deleting a feed would remove, and how much of it belongs to
+// users other than user_id.
+func (q *Queries) GetFeedStats(ctx context.Context, arg GetFeedStatsParams) (GetFeedStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedStats, arg.FeedID, arg.UserID)
+	var i GetFeedStatsRow
+	err := row.Scan(
+		&i.Followers,
+		&i.OtherFollowers,
+		&i.Posts,
+		&i.OtherStars,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, added_by, last_fetched_at, short_id FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -94,8 +174,9 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.UpdatedAt,
 			&i.Name,
 			&i.Url,
-			&i.UserID,
+			&i.AddedBy,
 			&i.LastFetchedAt,
+			&i.ShortID,
 		); err != nil {
 			return nil, err
 		}
@@ -111,7 +192,7 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, added_by, last_fetched_at, short_id FROM feeds
 ORDER BY last_fetched_at ASC NULLS FIRST
 `
 
@@ -124,12 +205,75 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.UpdatedAt,
 		&i.Name,
 		&i.Url,
-		&i.UserID,
+		&i.AddedBy,
 		&i.LastFetchedAt,
+		&i.ShortID,
 	)
 	return i, err
 }
 
+const getOrphanedFeeds = `-- name: GetOrphanedFeeds :many
+SELECT
+    feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.added_by, feeds.last_fetched_at, feeds.short_id,
+    (SELECT COUNT(*) FROM posts WHERE posts.feed_id = feeds.id) AS post_count
+FROM feeds
+WHERE NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM user_post_stars
+    INNER JOIN posts ON posts.id = user_post_stars.post_id
+    WHERE posts.feed_id = feeds.id
+)
+ORDER BY feeds.name
+`
+
+type GetOrphanedFeedsRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Name          string
+	Url           string
+	AddedBy       uuid.NullUUID
+	LastFetchedAt sql.NullTime
+	ShortID       int64
+	PostCount     int64
+}
+
+func (q *Queries) GetOrphanedFeeds(ctx context.Context) ([]GetOrphanedFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrphanedFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrphanedFeedsRow
+	for rows.Next() {
+		var i GetOrphanedFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.AddedBy,
+			&i.LastFetchedAt,
+			&i.ShortID,
+			&i.PostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 UPDATE feeds
 SET last_fetched_at = NOW(), updated_at = NOW()
@@ -140,3 +284,75 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
 	return err
 }
+
+const setFeedOwner = `-- name: SetFeedOwner :exec
+UPDATE feeds
+SET added_by = $2, updated_at = $3
+WHERE id = $1
+`
+
+type SetFeedOwnerParams struct {
+	ID        uuid.UUID
+	AddedBy   uuid.NullUUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedOwner(ctx context.Context, arg SetFeedOwnerParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedOwner, arg.ID, arg.AddedBy, arg.UpdatedAt)
+	return err
+}
+
+const transferFeeds = `-- name: TransferFeeds :execrows
+UPDATE feeds
+SET added_by = $2, updated_at = $3
+WHERE added_by = $1
+`
+
+type TransferFeedsParams struct {
+	AddedBy   uuid.NullUUID
+	AddedBy_2 uuid.NullUUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) TransferFeeds(ctx context.Context, arg TransferFeedsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, transferFeeds, arg.AddedBy, arg.AddedBy_2, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateFeed = `-- name: UpdateFeed :one
+UPDATE feeds
+SET name = $2, url = $3, updated_at = $4
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, added_by, last_fetched_at, short_id
+`
+
+type UpdateFeedParams struct {
+	ID        uuid.UUID
+	Name      string
+	Url       string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateFeed(ctx context.Context, arg UpdateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeed,
+		arg.ID,
+		arg.Name,
+		arg.Url,
+		arg.UpdatedAt,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.AddedBy,
+		&i.LastFetchedAt,
+		&i.ShortID,
+	)
+	return i, err
+}
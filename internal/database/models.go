@@ -11,14 +11,24 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+}
+
 type Feed struct {
 	ID            uuid.UUID
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	Name          string
 	Url           string
-	UserID        uuid.UUID
+	AddedBy       uuid.NullUUID
 	LastFetchedAt sql.NullTime
+	ShortID       int64
 }
 
 type FeedFollow struct {
@@ -27,22 +37,129 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Title     string
+	Folder    string
+	Position  int32
 }
 
-type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description string
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+type FeedRetention struct {
+	FeedID    uuid.UUID
+	KeepDays  sql.NullInt32
+	KeepPosts sql.NullInt32
+	UpdatedAt time.Time
 }
 
-type User struct {
+type FeverCredential struct {
+	UserID    uuid.UUID
+	ApiKey    string
+	CreatedAt time.Time
+}
+
+type FilterRule struct {
 	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	Field     string
+	MatchType string
+	Pattern   string
+	FeedID    uuid.NullUUID
+	Folder    sql.NullString
+	Action    string
+	Tag       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Post struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	SearchVector interface{}
+	ContentText  string
+	ShortID      int64
+	Author       string
+}
+
+type PostCategory struct {
+	PostID   uuid.UUID
+	Category string
+}
+
+type PostHide struct {
+	UserID   uuid.UUID
+	PostID   uuid.UUID
+	RuleID   uuid.UUID
+	HiddenAt time.Time
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type PostTag struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type PrunedPost struct {
+	Url      string
+	FeedID   uuid.UUID
+	PrunedAt time.Time
+	SeenAt   time.Time
+}
+
+type PublishToken struct {
+	UserID    uuid.UUID
+	TokenHash string
 	CreatedAt time.Time
+}
+
+type RetentionSetting struct {
+	ID        bool
+	KeepDays  int32
+	KeepPosts int32
 	UpdatedAt time.Time
+}
+
+type SavedSearch struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
 	Name      string
+	Query     string
+	TsQuery   sql.NullString
+	FeedID    uuid.NullUUID
+	Folder    sql.NullString
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Session struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type User struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Name           string
+	HashedPassword string
+	Role           string
+}
+
+type UserPostStar struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	StarredAt time.Time
 }
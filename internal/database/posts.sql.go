@@ -7,13 +7,292 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const browsePostsByFetched = `-- name: BrowsePostsByFetched :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.content_text, posts.short_id, posts.author,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+        AND user_post_stars.user_id = feed_follows.user_id
+    ) AS is_starred
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+AND ($2::uuid IS NULL OR posts.feed_id = $2)
+AND ($3::timestamp IS NULL OR posts.created_at >= $3)
+AND ($4::timestamp IS NULL OR posts.created_at < $4)
+AND (NOT $5::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id
+    AND post_reads.user_id = feed_follows.user_id
+))
+AND (
+    $6::timestamp IS NULL
+    OR (posts.created_at, posts.id) < ($6, $7::uuid)
+)
+AND (
+    $8::text IS NULL
+    OR feed_follows.folder = $8
+    OR starts_with(feed_follows.folder, $8 || '/')
+)
+AND (
+    $9::text IS NULL
+    OR posts.search_vector @@ to_tsquery('english', $9)
+)
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT $10
+OFFSET $11
+`
+
+type BrowsePostsByFetchedParams struct {
+	UserID     uuid.UUID
+	FeedID     uuid.NullUUID
+	Since      sql.NullTime
+	Until      sql.NullTime
+	UnreadOnly bool
+	CursorTime sql.NullTime
+	CursorID   uuid.NullUUID
+	Folder     sql.NullString
+	TsQuery    sql.NullString
+	Limit      int32
+	Offset     int32
+}
+
+type BrowsePostsByFetchedRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	SearchVector interface{}
+	ContentText  string
+	ShortID      int64
+	Author       string
+	FeedName     string
+	IsRead       bool
+	IsStarred    bool
+}
+
+func (q *Queries) BrowsePostsByFetched(ctx context.Context, arg BrowsePostsByFetchedParams) ([]BrowsePostsByFetchedRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePostsByFetched,
+		arg.UserID,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.UnreadOnly,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.Folder,
+		arg.TsQuery,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsByFetchedRow
+	for rows.Next() {
+		var i BrowsePostsByFetchedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SearchVector,
+			&i.ContentText,
+			&i.ShortID,
+			&i.Author,
+			&i.FeedName,
+			&i.IsRead,
+			&i.IsStarred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const browsePostsByPublished = `-- name: BrowsePostsByPublished :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.content_text, posts.short_id, posts.author,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+        AND user_post_stars.user_id = feed_follows.user_id
+    ) AS is_starred
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+AND ($2::uuid IS NULL OR posts.feed_id = $2)
+AND ($3::timestamp IS NULL OR posts.published_at >= $3)
+AND ($4::timestamp IS NULL OR posts.published_at < $4)
+AND (NOT $5::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id
+    AND post_reads.user_id = feed_follows.user_id
+))
+AND (
+    $6::timestamp IS NULL
+    OR (posts.published_at, posts.id) < ($6, $7::uuid)
+)
+AND (
+    $8::text IS NULL
+    OR feed_follows.folder = $8
+    OR starts_with(feed_follows.folder, $8 || '/')
+)
+AND (
+    $9::text IS NULL
+    OR posts.search_vector @@ to_tsquery('english', $9)
+)
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT $10
+OFFSET $11
+`
+
+type BrowsePostsByPublishedParams struct {
+	UserID     uuid.UUID
+	FeedID     uuid.NullUUID
+	Since      sql.NullTime
+	Until      sql.NullTime
+	UnreadOnly bool
+	CursorTime sql.NullTime
+	CursorID   uuid.NullUUID
+	Folder     sql.NullString
+	TsQuery    sql.NullString
+	Limit      int32
+	Offset     int32
+}
+
+type BrowsePostsByPublishedRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	SearchVector interface{}
+	ContentText  string
+	ShortID      int64
+	Author       string
+	FeedName     string
+	IsRead       bool
+	IsStarred    bool
+}
+
+func (q *Queries) BrowsePostsByPublished(ctx context.Context, arg BrowsePostsByPublishedParams) ([]BrowsePostsByPublishedRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePostsByPublished,
+		arg.UserID,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.UnreadOnly,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.Folder,
+		arg.TsQuery,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsByPublishedRow
+	for rows.Next() {
+		var i BrowsePostsByPublishedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SearchVector,
+			&i.ContentText,
+			&i.ShortID,
+			&i.Author,
+			&i.FeedName,
+			&i.IsRead,
+			&i.IsStarred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countPostsForUser = `-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+`
+
+func (q *Queries) CountPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content_text, author)
 VALUES (
     $1,
     $2,
@@ -22,9 +301,11 @@ VALUES (
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10
 )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, search_vector, content_text, short_id, author
 `
 
 type CreatePostParams struct {
@@ -36,6 +317,8 @@ type CreatePostParams struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	ContentText string
+	Author      string
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -48,7 +331,34 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.ContentText,
+		arg.Author,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.SearchVector,
+		&i.ContentText,
+		&i.ShortID,
+		&i.Author,
 	)
+	return i, err
+}
+
+const getPostByShortID = `-- name: GetPostByShortID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, search_vector, content_text, short_id, author FROM posts
+WHERE short_id = $1
+`
+
+func (q *Queries) GetPostByShortID(ctx context.Context, shortID int64) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByShortID, shortID)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -59,12 +369,16 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.SearchVector,
+		&i.ContentText,
+		&i.ShortID,
+		&i.Author,
 	)
 	return i, err
 }
 
 const getPostByURL = `-- name: GetPostByURL :one
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, search_vector, content_text, short_id, author FROM posts
 WHERE url = $1
 `
 
@@ -80,14 +394,192 @@ func (q *Queries) GetPostByURL(ctx context.Context, url string) (Post, error) {
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.SearchVector,
+		&i.ContentText,
+		&i.ShortID,
+		&i.Author,
 	)
 	return i, err
 }
 
+const getPostForUser = `-- name: GetPostForUser :one
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.content_text, posts.short_id, posts.author,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = $1
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+        AND user_post_stars.user_id = $1
+    ) AS is_starred
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+AND feed_follows.user_id = $1
+WHERE posts.id = $2
+`
+
+type GetPostForUserParams struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+type GetPostForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	SearchVector interface{}
+	ContentText  string
+	ShortID      int64
+	Author       string
+	FeedName     string
+	IsRead       bool
+	IsStarred    bool
+}
+
+func (q *Queries) GetPostForUser(ctx context.Context, arg GetPostForUserParams) (GetPostForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUser, arg.UserID, arg.ID)
+	var i GetPostForUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.SearchVector,
+		&i.ContentText,
+		&i.ShortID,
+		&i.Author,
+		&i.FeedName,
+		&i.IsRead,
+		&i.IsStarred,
+	)
+	return i, err
+}
+
+const getPostsForUserByShortID = `-- name: GetPostsForUserByShortID :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.content_text, posts.short_id, posts.author,
+    feeds.short_id AS feed_short_id,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+        AND user_post_stars.user_id = feed_follows.user_id
+    ) AS is_starred
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+AND ($2::bigint IS NULL OR posts.short_id > $2)
+AND ($3::bigint IS NULL OR posts.short_id < $3)
+AND ($4::bigint[] IS NULL OR posts.short_id = ANY($4::bigint[]))
+ORDER BY CASE WHEN $3::bigint IS NULL THEN posts.short_id ELSE -posts.short_id END
+LIMIT $5
+`
+
+type GetPostsForUserByShortIDParams struct {
+	UserID  uuid.UUID
+	SinceID sql.NullInt64
+	MaxID   sql.NullInt64
+	Ids     []int64
+	Limit   int32
+}
+
+type GetPostsForUserByShortIDRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	SearchVector interface{}
+	ContentText  string
+	ShortID      int64
+	Author       string
+	FeedShortID  int64
+	IsRead       bool
+	IsStarred    bool
+}
+
+func (q *Queries) GetPostsForUserByShortID(ctx context.Context, arg GetPostsForUserByShortIDParams) ([]GetPostsForUserByShortIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByShortID,
+		arg.UserID,
+		arg.SinceID,
+		arg.MaxID,
+		pq.Array(arg.Ids),
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByShortIDRow
+	for rows.Next() {
+		var i GetPostsForUserByShortIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SearchVector,
+			&i.ContentText,
+			&i.ShortID,
+			&i.Author,
+			&i.FeedShortID,
+			&i.IsRead,
+			&i.IsStarred,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsFromUser = `-- name: GetPostsFromUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.content_text, posts.short_id, posts.author, COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name, feeds.url AS feed_url FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
 WHERE feed_follows.user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
 ORDER BY posts.published_at DESC
 LIMIT $2
 `
@@ -97,15 +589,32 @@ type GetPostsFromUserParams struct {
 	Limit  int32
 }
 
-func (q *Queries) GetPostsFromUser(ctx context.Context, arg GetPostsFromUserParams) ([]Post, error) {
+type GetPostsFromUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	SearchVector interface{}
+	ContentText  string
+	ShortID      int64
+	Author       string
+	FeedName     string
+	FeedUrl      string
+}
+
+func (q *Queries) GetPostsFromUser(ctx context.Context, arg GetPostsFromUserParams) ([]GetPostsFromUserRow, error) {
 	rows, err := q.db.QueryContext(ctx, getPostsFromUser, arg.UserID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsFromUserRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsFromUserRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -115,6 +624,138 @@ func (q *Queries) GetPostsFromUser(ctx context.Context, arg GetPostsFromUserPara
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.SearchVector,
+			&i.ContentText,
+			&i.ShortID,
+			&i.Author,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadPostShortIDsForUser = `-- name: GetUnreadPostShortIDsForUser :many
+SELECT posts.short_id FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id
+    AND post_reads.user_id = feed_follows.user_id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+ORDER BY posts.short_id
+`
+
+func (q *Queries) GetUnreadPostShortIDsForUser(ctx context.Context, userID uuid.UUID) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadPostShortIDsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var short_id int64
+		if err := rows.Scan(&short_id); err != nil {
+			return nil, err
+		}
+		items = append(items, short_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    posts.feed_id,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    ts_rank(posts.search_vector, to_tsquery('english', $1)) AS rank,
+    ts_headline(
+        'english',
+        posts.title,
+        to_tsquery('english', $1),
+        'StartSel=[[, StopSel=]], HighlightAll=true'
+    ) AS title_headline,
+    ts_headline(
+        'english',
+        COALESCE(NULLIF(posts.content_text, ''), posts.description),
+        to_tsquery('english', $1),
+        'StartSel=[[, StopSel=]], MaxFragments=2, MaxWords=25, MinWords=10'
+    ) AS description_headline
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $2
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+AND posts.search_vector @@ to_tsquery('english', $1)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $3
+`
+
+type SearchPostsForUserParams struct {
+	Query  string
+	UserID uuid.UUID
+	Limit  int32
+}
+
+type SearchPostsForUserRow struct {
+	ID                  uuid.UUID
+	Title               string
+	Url                 string
+	PublishedAt         time.Time
+	FeedID              uuid.UUID
+	FeedName            string
+	Rank                float32
+	TitleHeadline       string
+	DescriptionHeadline string
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser, arg.Query, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsForUserRow
+	for rows.Next() {
+		var i SearchPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeedName,
+			&i.Rank,
+			&i.TitleHeadline,
+			&i.DescriptionHeadline,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// testQueries connects to the migrated database in $GATOR_TEST_DB_URL, and
+// skips the test if it isn't set. The tests delete everything in it.
+func testQueries(t *testing.T) *Queries {
+	t.Helper()
+	dbURL := os.Getenv("GATOR_TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("GATOR_TEST_DB_URL not set")
+	}
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return New(db)
+}
+
+func TestResetDeletesFeedsAndPosts(t *testing.T) {
+	q := testQueries(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	user, err := q.CreateUser(ctx, CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "reset-test",
+		Role:      "user",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	feed, err := q.CreateFeed(ctx, CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "Reset test",
+		Url:       "https://example.com/reset-test.xml",
+		AddedBy:   uuid.NullUUID{UUID: user.ID, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	// An orphaned feed, as left behind when its owner was deleted.
+	_, err = q.CreateFeed(ctx, CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "Orphaned",
+		Url:       "https://example.com/orphaned.xml",
+	})
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	post, err := q.CreatePost(ctx, CreatePostParams{
+		ID:          uuid.New(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Title:       "Post",
+		Url:         "https://example.com/reset-test/post",
+		PublishedAt: now,
+		FeedID:      feed.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+
+	if err = q.Reset(ctx); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+
+	feeds, err := q.GetFeeds(ctx)
+	if err != nil {
+		t.Fatalf("GetFeeds: %v", err)
+	}
+	if len(feeds) != 0 {
+		t.Errorf("got %d feeds after reset, want 0", len(feeds))
+	}
+	if _, err = q.GetPostByURL(ctx, post.Url); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetPostByURL after reset: got error %v, want sql.ErrNoRows", err)
+	}
+	users, err := q.GetUsers(ctx)
+	if err != nil {
+		t.Fatalf("GetUsers: %v", err)
+	}
+	if len(users) != 0 {
+		t.Errorf("got %d users after reset, want 0", len(users))
+	}
+}
@@ -2,22 +2,109 @@ package main
 
 import (
 	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/awbalessa/gator/internal/config"
 	"github.com/awbalessa/gator/internal/database"
 	_ "github.com/lib/pq"
 )
 
+type globalOptions struct {
+	output     string
+	format     string
+	formatFile string
+}
+
+func globalFlagSet(opts *globalOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("gator", flag.ContinueOnError)
+	addGlobalFlags(fs, opts)
+	return fs
+}
+
+// addGlobalFlags declares the global flags on fs. Commands declare them too,
+// so that they can also be given after the command name.
+func addGlobalFlags(fs *flag.FlagSet, opts *globalOptions) {
+	fs.StringVar(&opts.output, "output", outputText, "output format: "+strings.Join(outputFormats, ", "))
+	fs.StringVar(&opts.output, "o", outputText, "shorthand for --output")
+	fs.StringVar(&opts.format, "format", "", "Go template applied to each result, or the name of a template from the config")
+	fs.StringVar(&opts.formatFile, "format-file", "", "file containing a Go template applied to each result")
+}
+
+// withGlobalFlags returns s with its output rebuilt from the global flags
+// fs parsed into after, for when they were given after the command name.
+// They override those given before it.
+func withGlobalFlags(s *state, fs *flag.FlagSet, after globalOptions) (*state, error) {
+	opts, changed := s.opts, false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "output", "o":
+			opts.output = after.output
+		case "format":
+			opts.format = after.format
+		case "format-file":
+			opts.formatFile = after.formatFile
+		default:
+			return
+		}
+		changed = true
+	})
+	if !changed {
+		return s, nil
+	}
+	out, err := newOutput(opts, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	copied := *s
+	copied.opts = opts
+	copied.out = out
+	return &copied, nil
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("error: command required")
+	cmds := commands{
+		cmdToInfo: make(map[string]commandInfo),
+	}
+	registerCommands(&cmds)
+
+	var opts globalOptions
+	global := globalFlagSet(&opts)
+	global.SetOutput(io.Discard)
+	if err := global.Parse(os.Args[1:]); errors.Is(err, flag.ErrHelp) {
+		cmds.printHelp(os.Stdout)
+		return
+	} else if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	if global.NArg() < 1 {
+		cmds.printHelp(os.Stderr)
+		os.Exit(1)
 	}
+	cmd := command{
+		name: global.Arg(0),
+		args: global.Args()[1:],
+	}
+	if cmds.isStateless(cmd.name) {
+		if err := cmds.run(nil, cmd); err != nil {
+			log.Fatalf("failed to run command: %v", err)
+		}
+		return
+	}
+
 	cfg, err := config.Read()
 	if err != nil {
 		log.Fatalf("failed to read config: %v", err)
 	}
+	out, err := newOutput(opts, cfg)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
 	db, err := sql.Open("postgres", cfg.DatabaseURL)
 	if err != nil {
 		log.Fatalf("failed to open database connection: %v", err)
@@ -27,29 +114,515 @@ func main() {
 	}
 	dbQueries := database.New(db)
 	s := &state{
-		cfg: cfg,
-		db:  dbQueries,
-	}
-	cmds := commands{
-		cmdToHandler: make(map[string]func(*state, command) error),
-	}
-	cmds.register("login", handleLogin)
-	cmds.register("register", handleRegister)
-	cmds.register("reset", handleReset)
-	cmds.register("users", handleUsers)
-	cmds.register("agg", handleAgg)
-	cmds.register("addfeed", middlewareLoggedIn(handleAddFeed))
-	cmds.register("feeds", handleFeeds)
-	cmds.register("follow", middlewareLoggedIn(handleFollow))
-	cmds.register("following", middlewareLoggedIn(handleFollowing))
-	cmds.register("unfollow", middlewareLoggedIn(handleUnfollow))
-	cmds.register("browse", middlewareLoggedIn(handleBrowse))
-	cmd := command{
-		name: os.Args[1],
-		args: os.Args[2:],
+		cfg:  cfg,
+		db:   dbQueries,
+		conn: db,
+		out:  out,
+		opts: opts,
 	}
 
 	if err = cmds.run(s, cmd); err != nil {
 		log.Fatalf("failed to run command: %v", err)
 	}
 }
+
+func registerCommands(cmds *commands) {
+	cmds.register(commandInfo{
+		name:         "help",
+		summary:      "Show the list of commands or help for one command",
+		usage:        "[command]",
+		maxArgs:      1,
+		stateless:    true,
+		handler:      cmds.handleHelp,
+		completeArgs: []completer{cmds.completeCommandNames},
+	})
+	cmds.register(commandInfo{
+		name:         "completion",
+		summary:      "Print a shell completion script for bash, zsh or fish",
+		usage:        "<shell>",
+		minArgs:      1,
+		maxArgs:      1,
+		stateless:    true,
+		handler:      handleCompletion,
+		completeArgs: []completer{staticCompleter("bash", "zsh", "fish")},
+	})
+	cmds.register(commandInfo{
+		name:        "reader",
+		summary:     "Read followed feeds in a full-screen terminal UI",
+		userHandler: handleTUI,
+	})
+	cmds.register(commandInfo{
+		name:    "shell",
+		summary: "Run commands interactively with history and completion",
+		handler: cmds.handleShell,
+	})
+	cmds.register(commandInfo{
+		name:    "__complete",
+		summary: "List completion candidates for the given words",
+		maxArgs: unlimitedArgs,
+		hidden:  true,
+		rawArgs: true,
+		handler: cmds.handleComplete,
+	})
+	cmds.register(commandInfo{
+		name:         "login",
+		summary:      "Log in as an existing user with their password",
+		usage:        "<username>",
+		minArgs:      1,
+		maxArgs:      1,
+		handler:      handleLogin,
+		completeArgs: []completer{completeUsernames},
+	})
+	cmds.register(commandInfo{
+		name:    "register",
+		summary: "Create a user and log in as them",
+		usage:   "<username>",
+		minArgs: 1,
+		maxArgs: 1,
+		handler: handleRegister,
+	})
+	cmds.register(commandInfo{
+		name:    "logout",
+		summary: "Log out and end the session",
+		handler: handleLogout,
+	})
+	cmds.register(commandInfo{
+		name:         "passwd",
+		summary:      "Change your password, or as an admin set a user's, and log out other sessions",
+		usage:        "[username]",
+		maxArgs:      1,
+		userHandler:  handlePasswd,
+		completeArgs: []completer{completeUsernames},
+	})
+	cmds.register(commandInfo{
+		name:        "addtoken",
+		summary:     "Create an API token for scripts and HTTP clients",
+		usage:       "<name>",
+		minArgs:     1,
+		maxArgs:     1,
+		userHandler: handleAddToken,
+	})
+	cmds.register(commandInfo{
+		name:        "tokens",
+		summary:     "List your API tokens",
+		userHandler: handleTokens,
+	})
+	cmds.register(commandInfo{
+		name:         "revoketoken",
+		summary:      "Revoke an API token",
+		usage:        "<name>",
+		minArgs:      1,
+		maxArgs:      1,
+		userHandler:  handleRevokeToken,
+		completeArgs: []completer{completeTokenNames},
+	})
+	cmds.register(commandInfo{
+		name:        "reset",
+		summary:     "Delete every user, feed and post (admin only)",
+		setFlags:    setDestructiveFlags,
+		adminOnly:   true,
+		userHandler: handleReset,
+	})
+	cmds.register(commandInfo{
+		name:         "promote",
+		summary:      "Make a user the first admin of an install that has none (operators only)",
+		usage:        "<username>",
+		minArgs:      1,
+		maxArgs:      1,
+		handler:      handlePromote,
+		completeArgs: []completer{completeUsernames},
+	})
+	cmds.register(commandInfo{
+		name:         "setrole",
+		summary:      "Make a user an admin or an ordinary user (admin only)",
+		usage:        "<username> <user|admin>",
+		minArgs:      2,
+		maxArgs:      2,
+		adminOnly:    true,
+		userHandler:  handleSetRole,
+		completeArgs: []completer{completeUsernames, staticCompleter(roleUser, roleAdmin)},
+	})
+	cmds.register(commandInfo{
+		name:    "users",
+		summary: "List users",
+		handler: handleUsers,
+	})
+	cmds.register(commandInfo{
+		name:         "deluser",
+		summary:      "Delete a user, keeping feeds others follow (admin only)",
+		usage:        "<username>",
+		minArgs:      1,
+		maxArgs:      1,
+		setFlags:     setDelUserFlags,
+		adminOnly:    true,
+		userHandler:  handleDelUser,
+		completeArgs: []completer{completeUsernames},
+		completeFlags: map[string]completer{
+			"transfer-to": completeUsernames,
+		},
+	})
+	cmds.register(commandInfo{
+		name:         "renameuser",
+		summary:      "Rename a user",
+		usage:        "<username> <new name>",
+		minArgs:      2,
+		maxArgs:      2,
+		userHandler:  handleRenameUser,
+		completeArgs: []completer{completeUsernames},
+	})
+	cmds.register(commandInfo{
+		name:    "agg",
+		summary: "Fetch feeds continuously, one feed per interval",
+		usage:   "<interval>",
+		minArgs: 1,
+		maxArgs: 1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.Duration("prune-every", 0, "also prune old posts this often, e.g. 24h (0 never prunes, admin only)")
+		},
+		handler: handleAgg,
+	})
+	cmds.register(commandInfo{
+		name:        "addfeed",
+		summary:     "Add a feed and follow it",
+		usage:       "<name> <url>",
+		minArgs:     2,
+		maxArgs:     2,
+		userHandler: handleAddFeed,
+	})
+	cmds.register(commandInfo{
+		name:    "feeds",
+		summary: "List all feeds",
+		handler: handleFeeds,
+	})
+	cmds.register(commandInfo{
+		name:         "rmfeed",
+		summary:      "Delete a feed you added, for everyone following it",
+		usage:        "<feed url>",
+		minArgs:      1,
+		maxArgs:      1,
+		setFlags:     setDestructiveFlags,
+		userHandler:  handleRmFeed,
+		completeArgs: []completer{completeFeedURLs},
+	})
+	cmds.register(commandInfo{
+		name:    "editfeed",
+		summary: "Change the name or URL of a feed you added",
+		usage:   "<feed url>",
+		minArgs: 1,
+		maxArgs: 1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.String("name", "", "new name")
+			fs.String("url", "", "new URL")
+		},
+		userHandler:  handleEditFeed,
+		completeArgs: []completer{completeFeedURLs},
+	})
+	cmds.register(commandInfo{
+		name:    "gc",
+		summary: "Delete feeds nobody follows and their posts (admin only)",
+		setFlags: func(fs *flag.FlagSet) {
+			fs.Bool("dry-run", false, "list the feeds that would be deleted without deleting them")
+		},
+		adminOnly:   true,
+		userHandler: handleGC,
+	})
+	cmds.register(commandInfo{
+		name:         "retention",
+		summary:      "Show or change how long posts are kept, by default or for a feed",
+		usage:        "[feed url]",
+		maxArgs:      1,
+		setFlags:     setRetentionFlags,
+		userHandler:  handleRetention,
+		completeArgs: []completer{completeFeedURLs},
+	})
+	cmds.register(commandInfo{
+		name:    "prune",
+		summary: "Delete posts the retention policy no longer keeps, except starred ones (admin only)",
+		setFlags: func(fs *flag.FlagSet) {
+			fs.Bool("dry-run", false, "list how many posts would be deleted without deleting them")
+		},
+		adminOnly:   true,
+		userHandler: handlePrune,
+	})
+	cmds.register(commandInfo{
+		name:         "transferfeed",
+		summary:      "Give a feed you own to another user",
+		usage:        "<feed url> <username>",
+		minArgs:      2,
+		maxArgs:      2,
+		userHandler:  handleTransferFeed,
+		completeArgs: []completer{completeFeedURLs, completeUsernames},
+	})
+	cmds.register(commandInfo{
+		name:         "follow",
+		summary:      "Follow an existing feed",
+		usage:        "<feed url>",
+		minArgs:      1,
+		maxArgs:      1,
+		userHandler:  handleFollow,
+		completeArgs: []completer{completeFeedURLs},
+	})
+	cmds.register(commandInfo{
+		name:        "following",
+		summary:     "List followed feeds by folder with their unread counts",
+		userHandler: handleFollowing,
+	})
+	cmds.register(commandInfo{
+		name:    "editfollow",
+		summary: "Set your own title, folder and position for a followed feed",
+		usage:   "<feed url>",
+		minArgs: 1,
+		maxArgs: 1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.String("title", "", "title to show instead of the feed's name (empty to reset)")
+			fs.String("folder", "", "folder to put the feed in, nested with \"/\" (empty for the top level)")
+			fs.Int("position", 0, "position of the feed within its folder, lowest first")
+		},
+		userHandler:  handleEditFollow,
+		completeArgs: []completer{completeFollowedFeedURLs},
+		completeFlags: map[string]completer{
+			"folder": completeFolders,
+		},
+	})
+	cmds.register(commandInfo{
+		name:         "unfollow",
+		summary:      "Stop following a feed",
+		usage:        "<feed url>",
+		minArgs:      1,
+		maxArgs:      1,
+		userHandler:  handleUnfollow,
+		completeArgs: []completer{completeFollowedFeedURLs},
+	})
+	cmds.register(commandInfo{
+		name:        "browse",
+		summary:     "Show posts from followed feeds and mark them read",
+		usage:       "[limit]",
+		maxArgs:     1,
+		setFlags:    setBrowseFlags,
+		userHandler: handleBrowse,
+		completeFlags: map[string]completer{
+			"feed":   completeFollowedFeedURLs,
+			"folder": completeFolders,
+			"saved":  completeSavedSearches,
+			"sort":   staticCompleter(sortPublished, sortFetched),
+		},
+	})
+	cmds.register(commandInfo{
+		name:        "read",
+		summary:     "Mark posts, or every post in a feed, as read",
+		usage:       "<post id>... | all <feed url>",
+		minArgs:     1,
+		maxArgs:     unlimitedArgs,
+		userHandler: handleRead,
+	})
+	cmds.register(commandInfo{
+		name:        "unread",
+		summary:     "Mark posts, or every post in a feed, as unread",
+		usage:       "<post id>... | all <feed url>",
+		minArgs:     1,
+		maxArgs:     unlimitedArgs,
+		userHandler: handleUnread,
+	})
+	cmds.register(commandInfo{
+		name:        "star",
+		summary:     "Star posts to keep them",
+		usage:       "<post id>...",
+		minArgs:     1,
+		maxArgs:     unlimitedArgs,
+		userHandler: handleStar,
+	})
+	cmds.register(commandInfo{
+		name:        "unstar",
+		summary:     "Remove the star from posts",
+		usage:       "<post id>...",
+		minArgs:     1,
+		maxArgs:     unlimitedArgs,
+		userHandler: handleUnstar,
+	})
+	cmds.register(commandInfo{
+		name:        "starred",
+		summary:     "List starred posts",
+		usage:       "[limit]",
+		maxArgs:     1,
+		userHandler: handleStarred,
+	})
+	cmds.register(commandInfo{
+		name:        "tag",
+		summary:     "Tag a post",
+		usage:       "<post id> <tag>...",
+		minArgs:     2,
+		maxArgs:     unlimitedArgs,
+		userHandler: handleTag,
+	})
+	cmds.register(commandInfo{
+		name:        "untag",
+		summary:     "Remove tags from a post",
+		usage:       "<post id> <tag>...",
+		minArgs:     2,
+		maxArgs:     unlimitedArgs,
+		userHandler: handleUntag,
+	})
+	cmds.register(commandInfo{
+		name:        "tags",
+		summary:     "List your tags",
+		userHandler: handleTags,
+	})
+	cmds.register(commandInfo{
+		name:    "tagged",
+		summary: "List posts with a tag",
+		usage:   "<tag>",
+		minArgs: 1,
+		maxArgs: 1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.Int("limit", 20, "maximum number of posts")
+			fs.Bool("categories", false, "also list posts in followed feeds whose feed category matches the tag")
+		},
+		userHandler:  handleTagged,
+		completeArgs: []completer{completeTags},
+	})
+	cmds.register(commandInfo{
+		name:        "addrule",
+		summary:     "Add a rule that hides, marks read, stars or tags matching posts",
+		usage:       "<name> <keyword or regex>",
+		minArgs:     2,
+		maxArgs:     2,
+		setFlags:    setAddRuleFlags,
+		userHandler: handleAddRule,
+		completeFlags: map[string]completer{
+			"field":  staticCompleter(ruleFields...),
+			"feed":   completeFollowedFeedURLs,
+			"folder": completeFolders,
+			"action": staticCompleter(ruleActions...),
+			"tag":    completeTags,
+		},
+	})
+	cmds.register(commandInfo{
+		name:        "rules",
+		summary:     "List your filter rules",
+		userHandler: handleRules,
+	})
+	cmds.register(commandInfo{
+		name:         "rmrule",
+		summary:      "Remove a filter rule, showing the posts it hid",
+		usage:        "<name>",
+		minArgs:      1,
+		maxArgs:      1,
+		userHandler:  handleRmRule,
+		completeArgs: []completer{completeRuleNames},
+	})
+	cmds.register(commandInfo{
+		name:         "applyrules",
+		summary:      "Apply filter rules to posts already fetched",
+		usage:        "[name]...",
+		maxArgs:      unlimitedArgs,
+		setFlags:     setApplyRulesFlags,
+		userHandler:  handleApplyRules,
+		completeArgs: []completer{completeRuleNames},
+	})
+	cmds.register(commandInfo{
+		name:    "search",
+		summary: "Search posts in followed feeds",
+		usage:   "[--] <query>...",
+		minArgs: 1,
+		maxArgs: unlimitedArgs,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.Int("limit", 20, "maximum number of results")
+		},
+		userHandler: handleSearch,
+	})
+	cmds.register(commandInfo{
+		name:        "savesearch",
+		summary:     "Save a search to browse like a feed",
+		usage:       "<name> [--] [query]...",
+		minArgs:     1,
+		maxArgs:     unlimitedArgs,
+		setFlags:    setSaveSearchFlags,
+		userHandler: handleSaveSearch,
+		completeFlags: map[string]completer{
+			"feed":   completeFollowedFeedURLs,
+			"folder": completeFolders,
+		},
+	})
+	cmds.register(commandInfo{
+		name:        "searches",
+		summary:     "List saved searches with their unread counts",
+		userHandler: handleSearches,
+	})
+	cmds.register(commandInfo{
+		name:         "rmsearch",
+		summary:      "Remove a saved search",
+		usage:        "<name>",
+		minArgs:      1,
+		maxArgs:      1,
+		userHandler:  handleRmSearch,
+		completeArgs: []completer{completeSavedSearches},
+	})
+	cmds.register(commandInfo{
+		name:        "feverpass",
+		summary:     "Set the password Fever API clients sign in with",
+		userHandler: handleFeverPass,
+	})
+	cmds.register(commandInfo{
+		name:        "publish",
+		summary:     "Print posts from followed feeds as an RSS or Atom feed",
+		setFlags:    setPublishFlags,
+		userHandler: handlePublish,
+		completeFlags: map[string]completer{
+			"type": staticCompleter(publishRSS, publishAtom),
+		},
+	})
+	cmds.register(commandInfo{
+		name:        "share",
+		summary:     "Let gator serve publish your feed at a secret URL",
+		setFlags:    setShareFlags,
+		userHandler: handleShare,
+		completeFlags: map[string]completer{
+			"type": staticCompleter(publishRSS, publishAtom),
+		},
+	})
+	cmds.register(commandInfo{
+		name:     "serve",
+		summary:  "Serve the web reader and a JSON API over HTTP",
+		setFlags: setServeFlags,
+		handler:  handleServe,
+	})
+}
+
+// newOutput builds the printer for the global output options.
+func newOutput(opts globalOptions, cfg *config.Config) (*printer, error) {
+	out, err := newPrinter(opts.output, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	if err = setOutputTemplate(out, cfg, opts.format, opts.formatFile); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// setOutputTemplate configures out from the --format and --format-file
+// options. A --format value naming a template from the config uses that
+// template; anything else is parsed as a template itself.
+func setOutputTemplate(out *printer, cfg *config.Config, format, formatFile string) error {
+	if format == "" && formatFile == "" {
+		return nil
+	}
+	if format != "" && formatFile != "" {
+		return fmt.Errorf("--format and --format-file cannot be used together")
+	}
+	if !out.isText() {
+		return fmt.Errorf("--format cannot be combined with --output %s", out.format)
+	}
+
+	text := format
+	if formatFile != "" {
+		data, err := os.ReadFile(formatFile)
+		if err != nil {
+			return fmt.Errorf("error reading template file: %v", err)
+		}
+		text = string(data)
+	} else if named, ok := cfg.Template(format); ok {
+		text = named
+	}
+	return out.setTemplate(text)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+	outputTSV   = "tsv"
+)
+
+var outputFormats = []string{outputText, outputJSON, outputYAML, outputTable, outputTSV}
+
+// printer writes command results in the format picked with --output. Results
+// are structs (or slices of structs) whose json tags name the fields; the same
+// names are used for every format so scripts can rely on them.
+//
+// When a template is set with --format it takes precedence over the output
+// format and is executed once per result, using the Go field names.
+type printer struct {
+	format string
+	tmpl   *template.Template
+	w      io.Writer
+}
+
+var templateFuncs = template.FuncMap{
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"trunc": func(n int, s string) string {
+		runes := []rune(s)
+		if len(runes) <= n {
+			return s
+		}
+		return string(runes[:n]) + "…"
+	},
+	"join": strings.Join,
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	for _, f := range outputFormats {
+		if f == format {
+			return &printer{format: format, w: w}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown output format %q: must be one of %s", format, strings.Join(outputFormats, ", "))
+}
+
+// setTemplate parses text as the per-result output template.
+func (p *printer) setTemplate(text string) error {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %v", err)
+	}
+	p.tmpl = tmpl
+	return nil
+}
+
+// print writes v in the configured format. In text mode it calls text
+// instead, which prints the command's human-readable output.
+func (p *printer) print(v any, text func()) error {
+	if p.tmpl != nil {
+		return p.writeTemplate(v)
+	}
+	switch p.format {
+	case outputJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		return p.writeYAML(v)
+	case outputTable:
+		return p.writeTable(v)
+	case outputTSV:
+		return p.writeTSV(v)
+	default:
+		text()
+		return nil
+	}
+}
+
+func (p *printer) isText() bool {
+	return p.format == outputText && p.tmpl == nil
+}
+
+func (p *printer) writeTemplate(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		if err := p.tmpl.Execute(p.w, v); err != nil {
+			return fmt.Errorf("error executing template: %v", err)
+		}
+		return nil
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := p.tmpl.Execute(p.w, rv.Index(i).Interface()); err != nil {
+			return fmt.Errorf("error executing template: %v", err)
+		}
+	}
+	return nil
+}
+
+// writeYAML goes through JSON first so both formats share the json tags and
+// field order.
+func (p *printer) writeYAML(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshalling: %v", err)
+	}
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("error converting to yaml: %v", err)
+	}
+	clearStyle(&node)
+	enc := yaml.NewEncoder(p.w)
+	enc.SetIndent(2)
+	if err = enc.Encode(&node); err != nil {
+		return fmt.Errorf("error encoding yaml: %v", err)
+	}
+	return enc.Close()
+}
+
+// clearStyle drops the flow and quoting styles the decoder kept from the
+// JSON input so the encoder emits block-style YAML.
+func clearStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		clearStyle(child)
+	}
+}
+
+func (p *printer) writeTable(v any) error {
+	headers, rows := tabulate(v)
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for i := range headers {
+		headers[i] = strings.ToUpper(headers[i])
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		for i := range row {
+			row[i] = strings.Join(strings.Fields(row[i]), " ")
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) writeTSV(v any) error {
+	headers, rows := tabulate(v)
+	escaper := strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+	fmt.Fprintln(p.w, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		for i := range row {
+			row[i] = escaper.Replace(row[i])
+		}
+		if _, err := fmt.Fprintln(p.w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tabulate flattens a struct or a slice of structs into a header row and one
+// row of cells per element, following the same rules as encoding/json for
+// tags and embedded structs.
+func tabulate(v any) ([]string, [][]string) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	var elems []reflect.Value
+	var elemType reflect.Type
+	if rv.Kind() == reflect.Slice {
+		elemType = rv.Type().Elem()
+		for i := 0; i < rv.Len(); i++ {
+			elems = append(elems, reflect.Indirect(rv.Index(i)))
+		}
+	} else {
+		elemType = rv.Type()
+		elems = append(elems, rv)
+	}
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+
+	cols := columns(elemType, nil)
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = c.name
+	}
+	rows := make([][]string, 0, len(elems))
+	for _, elem := range elems {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = formatCell(elem.FieldByIndex(c.index))
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+type column struct {
+	name  string
+	index []int
+}
+
+func columns(t reflect.Type, prefix []int) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		index := append(append([]int{}, prefix...), i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			cols = append(cols, columns(f.Type, index)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		cols = append(cols, column{name: name, index: index})
+	}
+	return cols
+}
+
+func formatCell(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch val := v.Interface().(type) {
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case []string:
+		return strings.Join(val, ",")
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+)
+
+const (
+	publishRSS  = "rss"
+	publishAtom = "atom"
+
+	defaultPublishLimit = 50
+)
+
+// digest is a user's timeline ready to be written as a feed document.
+type digest struct {
+	title   string
+	selfURL string
+	user    database.User
+	posts   []database.GetPostsFromUserRow
+	updated time.Time
+}
+
+func loadDigest(ctx context.Context, db *database.Queries, user database.User, limit int32) (digest, error) {
+	posts, err := db.GetPostsFromUser(ctx, database.GetPostsFromUserParams{
+		UserID: user.ID,
+		Limit:  limit,
+	})
+	if err != nil {
+		return digest{}, fmt.Errorf("error getting posts from user: %v", err)
+	}
+	d := digest{
+		title: fmt.Sprintf("%s's gator digest", user.Name),
+		user:  user,
+		posts: posts,
+	}
+	for _, post := range posts {
+		if post.CreatedAt.After(d.updated) {
+			d.updated = post.CreatedAt
+		}
+	}
+	if d.updated.IsZero() {
+		d.updated = user.CreatedAt
+	}
+	return d, nil
+}
+
+func publishPath(username, kind string) string {
+	return fmt.Sprintf("/users/%s/feed.%s", username, kind)
+}
+
+func setPublishFlags(fs *flag.FlagSet) {
+	fs.String("type", publishRSS, "document type: rss or atom")
+	fs.Int("limit", defaultPublishLimit, "maximum number of posts to include")
+	fs.String("title", "", "feed title (default \"<user>'s gator digest\")")
+	fs.String("base-url", "http://"+defaultServeAddr, "URL gator serve is reachable at, used for the feed's self link")
+}
+
+// handlePublish writes the logged in user's timeline to stdout as an RSS 2.0
+// or Atom document, the same one "gator serve" publishes.
+func handlePublish(s *state, cmd command, user database.User) error {
+	kind := cmd.flagString("type")
+	if kind != publishRSS && kind != publishAtom {
+		return fmt.Errorf("unknown feed type %q: must be %s or %s", kind, publishRSS, publishAtom)
+	}
+	if cmd.flagInt("limit") < 1 {
+		return fmt.Errorf("limit must be positive")
+	}
+
+	d, err := loadDigest(context.Background(), s.db, user, int32(cmd.flagInt("limit")))
+	if err != nil {
+		return err
+	}
+	if title := cmd.flagString("title"); title != "" {
+		d.title = title
+	}
+	d.selfURL = strings.TrimSuffix(cmd.flagString("base-url"), "/") + publishPath(user.Name, kind)
+	return d.write(os.Stdout, kind)
+}
+
+func setShareFlags(fs *flag.FlagSet) {
+	fs.String("type", publishRSS, "document type of the printed URL: rss or atom")
+	fs.String("base-url", "http://"+defaultServeAddr, "URL gator serve is reachable at")
+	fs.Bool("off", false, "stop serving the feed")
+}
+
+// handleShare lets "gator serve" publish the logged in user's timeline at a
+// URL with a secret token, printing that URL. Running it again replaces the
+// token, so anyone holding the old URL loses access.
+func handleShare(s *state, cmd command, user database.User) error {
+	if cmd.flagBool("off") {
+		if _, err := s.db.DeletePublishToken(context.Background(), user.ID); err != nil {
+			return fmt.Errorf("error removing publish token: %v", err)
+		}
+		msg := "Your feed is no longer published"
+		return s.out.print(messageView{Message: msg}, func() {
+			fmt.Println(msg)
+		})
+	}
+	kind := cmd.flagString("type")
+	if kind != publishRSS && kind != publishAtom {
+		return fmt.Errorf("unknown feed type %q: must be %s or %s", kind, publishRSS, publishAtom)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	err = s.db.SetPublishToken(context.Background(), database.SetPublishTokenParams{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error setting publish token: %v", err)
+	}
+	view := shareView{
+		Url: strings.TrimSuffix(cmd.flagString("base-url"), "/") + publishPath(user.Name, kind) + "?token=" + url.QueryEscape(token),
+	}
+	return s.out.print(view, func() {
+		fmt.Printf("Your feed is published at:\n\n    %s\n\n", view.Url)
+		fmt.Println("Anyone with this URL can read it. Run \"gator share\" again for a new URL or \"gator share --off\" to stop.")
+	})
+}
+
+// handlePublished serves a user's timeline at /users/{name}/feed.rss and
+// /users/{name}/feed.atom so other tools can subscribe to it. Only requests
+// with the token from "gator share" get the feed; everyone else gets a 404,
+// the same as for a user who doesn't exist.
+func (srv *server) handlePublished(w http.ResponseWriter, r *http.Request) {
+	kind := strings.TrimPrefix(r.PathValue("file"), "feed.")
+	token := r.URL.Query().Get("token")
+	if (kind != publishRSS && kind != publishAtom) || token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	user, err := srv.s.db.GetUserByPublishToken(r.Context(), database.GetUserByPublishTokenParams{
+		Name:      r.PathValue("name"),
+		TokenHash: hashToken(token),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		respondInternalError(w, "error getting user", err)
+		return
+	}
+
+	d, err := loadDigest(r.Context(), srv.s.db, user, defaultPublishLimit)
+	if err != nil {
+		respondInternalError(w, "error building feed", err)
+		return
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	d.selfURL = scheme + "://" + r.Host + r.URL.RequestURI()
+
+	if kind == publishAtom {
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	}
+	w.Header().Set("Last-Modified", d.updated.UTC().Format(http.TimeFormat))
+	if err = d.write(w, kind); err != nil {
+		respondInternalError(w, "error writing feed", err)
+	}
+}
+
+func (d digest) write(w io.Writer, kind string) error {
+	var doc any
+	if kind == publishAtom {
+		doc = d.atom()
+	} else {
+		doc = d.rss()
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("error encoding feed: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	LastBuildDate string       `xml:"lastBuildDate"`
+	Generator     string       `xml:"generator"`
+	AtomLink      rssAtomLink  `xml:"atom:link"`
+	Items         []rssOutItem `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssOutItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	PubDate     string    `xml:"pubDate"`
+	Guid        rssGuid   `xml:"guid"`
+	Source      rssSource `xml:"source"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	Url  string `xml:"url,attr"`
+	Name string `xml:",chardata"`
+}
+
+func (d digest) rss() rssDocument {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         d.title,
+			Link:          d.selfURL,
+			Description:   fmt.Sprintf("Posts from the feeds %s follows, collected by gator", d.user.Name),
+			LastBuildDate: d.updated.Format(time.RFC1123Z),
+			Generator:     "gator",
+			AtomLink:      rssAtomLink{Href: d.selfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, post := range d.posts {
+		doc.Channel.Items = append(doc.Channel.Items, rssOutItem{
+			Title:       post.Title,
+			Link:        post.Url,
+			Description: post.Description,
+			PubDate:     post.PublishedAt.Format(time.RFC1123Z),
+			Guid:        rssGuid{IsPermaLink: true, Value: post.Url},
+			Source:      rssSource{Url: post.FeedUrl, Name: post.FeedName},
+		})
+	}
+	return doc
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Link      []atomLink  `xml:"link"`
+	Author    atomPerson  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Link      atomLink   `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   atomText   `xml:"summary"`
+	Source    atomSource `xml:"source"`
+}
+
+type atomSource struct {
+	Title string   `xml:"title"`
+	Link  atomLink `xml:"link"`
+}
+
+func (d digest) atom() atomFeed {
+	feed := atomFeed{
+		Title:     d.title,
+		ID:        "urn:uuid:" + d.user.ID.String(),
+		Updated:   d.updated.Format(time.RFC3339),
+		Link:      []atomLink{{Href: d.selfURL, Rel: "self", Type: "application/atom+xml"}},
+		Author:    atomPerson{Name: d.user.Name},
+		Generator: "gator",
+	}
+	for _, post := range d.posts {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:     post.Title,
+			ID:        "urn:uuid:" + post.ID.String(),
+			Link:      atomLink{Href: post.Url, Rel: "alternate"},
+			Published: post.PublishedAt.Format(time.RFC3339),
+			Updated:   post.UpdatedAt.Format(time.RFC3339),
+			Summary:   atomText{Type: "html", Value: post.Description},
+			Source: atomSource{
+				Title: post.FeedName,
+				Link:  atomLink{Href: post.FeedUrl, Rel: "self"},
+			},
+		})
+	}
+	return feed
+}
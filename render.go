@@ -0,0 +1,486 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/term"
+)
+
+const (
+	ansiItalic    = "\033[3m"
+	ansiUnderline = "\033[4m"
+
+	defaultRenderWidth = 80
+)
+
+// renderOptions controls how post HTML is turned into text.
+type renderOptions struct {
+	// width wraps lines at this many columns; 0 leaves every paragraph on a
+	// single line.
+	width int
+	// ansi styles headings, emphasis and code with terminal escape codes.
+	ansi bool
+}
+
+// terminalRenderOptions renders for stdout: styled and wrapped to the
+// terminal when there is one, plain and 80 columns wide otherwise.
+func terminalRenderOptions() renderOptions {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		return renderOptions{width: width, ansi: true}
+	}
+	return renderOptions{width: defaultRenderWidth}
+}
+
+// renderHTML converts an HTML fragment, such as a feed item description,
+// into readable text. Block elements become paragraphs separated by blank
+// lines, lists get bullets or numbers, preformatted text keeps its layout
+// and links are replaced by numbered footnotes listed at the end. Scripts,
+// styles and everything else that is not content is dropped.
+func renderHTML(src string, opts renderOptions) string {
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		// The parser only fails on read errors, which a strings.Reader
+		// never returns, but plain text is a safe fallback regardless.
+		return stripControl(src)
+	}
+
+	r := &htmlRenderer{opts: opts}
+	for _, n := range nodes {
+		r.walk(n)
+	}
+	r.closeBlock()
+
+	for i, link := range r.links {
+		r.writeText(fmt.Sprintf("[%d] %s", i+1, link))
+		r.flush()
+	}
+	return strings.Join(r.lines, "\n")
+}
+
+// stripControl removes the control characters in s except newlines and
+// tabs. Feeds are untrusted, and escape sequences in them could retitle or
+// otherwise take over the terminal they are printed to.
+func stripControl(s string) string {
+	return strings.Map(func(c rune) rune {
+		if unicode.IsControl(c) && c != '\n' && c != '\t' {
+			return -1
+		}
+		return c
+	}, s)
+}
+
+// postContent returns the cached plain text of a post, rendering the HTML
+// description for posts fetched before the text was cached. Text cached
+// before control characters were stripped is cleaned up here.
+func postContent(contentText, description string) string {
+	if contentText != "" || description == "" {
+		return stripControl(contentText)
+	}
+	return renderHTML(description, renderOptions{})
+}
+
+type htmlList struct {
+	ordered bool
+	next    int
+}
+
+type htmlRenderer struct {
+	opts  renderOptions
+	lines []string
+
+	// inline collects the text of the paragraph being built.
+	inline  strings.Builder
+	hasText bool
+	space   bool
+
+	// indent holds one prefix per open list item or blockquote; marker
+	// replaces the innermost one on the first line of a list item.
+	indent []string
+	marker string
+	// blank is set when the next paragraph must be preceded by a blank line.
+	blank bool
+
+	lists  []htmlList
+	styles []string
+	links  []string
+	pre    int
+}
+
+func (r *htmlRenderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if r.pre > 0 {
+			r.inline.WriteString(stripControl(n.Data))
+			r.hasText = true
+		} else {
+			r.writeText(n.Data)
+		}
+		return
+	case html.ElementNode:
+	default:
+		r.walkChildren(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Title, atom.Iframe, atom.Noscript, atom.Object, atom.Svg, atom.Form:
+	case atom.Br:
+		if r.pre > 0 {
+			r.inline.WriteString("\n")
+		} else {
+			r.flush()
+		}
+	case atom.Hr:
+		r.closeBlock()
+		r.writeText(strings.Repeat("─", 3))
+		r.closeBlock()
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.closeBlock()
+		r.styled(ansiBold, n)
+		r.closeBlock()
+	case atom.Ul, atom.Ol:
+		// Nested lists continue the enclosing item without blank lines.
+		nested := len(r.lists) > 0
+		r.flush()
+		if !nested {
+			r.blank = len(r.lines) > 0
+		}
+		list := htmlList{ordered: n.DataAtom == atom.Ol, next: 1}
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			list.next = start
+		}
+		r.lists = append(r.lists, list)
+		r.walkChildren(n)
+		r.lists = r.lists[:len(r.lists)-1]
+		r.flush()
+		r.blank = !nested && len(r.lines) > 0
+	case atom.Li:
+		r.flush()
+		marker := "• "
+		if len(r.lists) > 0 && r.lists[len(r.lists)-1].ordered {
+			list := &r.lists[len(r.lists)-1]
+			marker = fmt.Sprintf("%d. ", list.next)
+			list.next++
+		}
+		r.indent = append(r.indent, strings.Repeat(" ", len([]rune(marker))))
+		r.marker = marker
+		r.walkChildren(n)
+		r.flush()
+		r.indent = r.indent[:len(r.indent)-1]
+		r.marker = ""
+		r.blank = false
+	case atom.Blockquote:
+		r.closeBlock()
+		r.indent = append(r.indent, "> ")
+		r.walkChildren(n)
+		r.closeBlock()
+		r.indent = r.indent[:len(r.indent)-1]
+	case atom.Pre:
+		r.closeBlock()
+		r.pre++
+		r.walkChildren(n)
+		r.closeBlock()
+		r.pre--
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		if r.pre > 0 {
+			r.walkChildren(n)
+		} else if r.opts.ansi {
+			r.styled(ansiDim, n)
+		} else {
+			r.writeText("`")
+			r.space = false
+			r.walkChildren(n)
+			r.inline.WriteString("`")
+		}
+	case atom.Strong, atom.B:
+		r.styled(ansiBold, n)
+	case atom.Em, atom.I, atom.Cite:
+		r.styled(ansiItalic, n)
+	case atom.U, atom.Ins:
+		r.styled(ansiUnderline, n)
+	case atom.A:
+		r.walkChildren(n)
+		href := attr(n, "href")
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return
+		}
+		if strings.TrimSpace(textContent(n)) == href {
+			return
+		}
+		r.links = append(r.links, stripControl(href))
+		r.inline.WriteString(fmt.Sprintf("[%d]", len(r.links)))
+		r.hasText = true
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			r.writeText("[image: " + alt + "]")
+		} else {
+			r.writeText("[image]")
+		}
+	case atom.Td, atom.Th:
+		if r.hasText {
+			r.writeText(" | ")
+		}
+		r.walkChildren(n)
+	case atom.Tr, atom.Dt:
+		r.flush()
+		r.walkChildren(n)
+		r.flush()
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main,
+		atom.Aside, atom.Nav, atom.Figure, atom.Figcaption, atom.Table, atom.Dl, atom.Dd, atom.Address:
+		r.closeBlock()
+		r.walkChildren(n)
+		r.closeBlock()
+	default:
+		r.walkChildren(n)
+	}
+}
+
+func (r *htmlRenderer) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+// styled renders the children of n with an ANSI style, restoring any outer
+// styles afterwards. Without ANSI output it renders them unchanged.
+func (r *htmlRenderer) styled(code string, n *html.Node) {
+	if !r.opts.ansi {
+		r.walkChildren(n)
+		return
+	}
+	if r.space {
+		r.inline.WriteByte(' ')
+		r.space = false
+	}
+	r.styles = append(r.styles, code)
+	r.inline.WriteString(code)
+	r.walkChildren(n)
+	r.styles = r.styles[:len(r.styles)-1]
+	r.inline.WriteString(ansiReset + strings.Join(r.styles, ""))
+}
+
+// writeText adds text to the current paragraph, collapsing runs of
+// whitespace into single spaces the way a browser would and dropping
+// control characters.
+func (r *htmlRenderer) writeText(text string) {
+	for _, c := range text {
+		switch c {
+		case ' ', '\t', '\n', '\r', '\f':
+			r.space = r.hasText
+		default:
+			if unicode.IsControl(c) {
+				continue
+			}
+			if r.space {
+				r.inline.WriteByte(' ')
+				r.space = false
+			}
+			r.inline.WriteRune(c)
+			r.hasText = true
+		}
+	}
+}
+
+// closeBlock ends the current paragraph and asks for a blank line before
+// the next one.
+func (r *htmlRenderer) closeBlock() {
+	r.flush()
+	r.blank = len(r.lines) > 0
+}
+
+// flush turns the current paragraph into wrapped, indented lines.
+func (r *htmlRenderer) flush() {
+	text := r.inline.String()
+	hasText := r.hasText
+	r.inline.Reset()
+	r.hasText = false
+	r.space = false
+	if len(r.styles) > 0 {
+		r.inline.WriteString(strings.Join(r.styles, ""))
+	}
+	if !hasText {
+		return
+	}
+
+	if r.blank && len(r.lines) > 0 {
+		r.lines = append(r.lines, "")
+	}
+	r.blank = false
+
+	prefix := strings.Join(r.indent, "")
+	first := prefix
+	if r.marker != "" {
+		first = strings.Join(r.indent[:len(r.indent)-1], "") + r.marker
+		r.marker = ""
+	}
+	width := 0
+	if r.opts.width > 0 {
+		width = max(r.opts.width-visibleLen(prefix), 10)
+	}
+
+	var lines []string
+	if r.pre > 0 {
+		text = strings.Trim(text, "\n")
+		for _, line := range strings.Split(text, "\n") {
+			for _, part := range breakStyled("    "+strings.TrimRight(line, " \t"), width) {
+				if r.opts.ansi {
+					part = ansiDim + part + ansiReset
+				}
+				lines = append(lines, part)
+			}
+		}
+	} else {
+		lines = wrapStyled(text, width)
+	}
+	for i, line := range lines {
+		if i == 0 {
+			r.lines = append(r.lines, first+line)
+		} else {
+			r.lines = append(r.lines, prefix+line)
+		}
+	}
+}
+
+// wrapStyled wraps text at width visible columns. ANSI styles that span a
+// line break are closed at the end of the line and reopened on the next, so
+// every line can be printed on its own.
+func wrapStyled(text string, width int) []string {
+	if width < 1 {
+		return []string{text}
+	}
+	var lines []string
+	var line strings.Builder
+	lineLen := 0
+	active := ""
+	for _, word := range strings.Split(text, " ") {
+		wordLen := visibleLen(word)
+		if lineLen > 0 && lineLen+1+wordLen > width {
+			lines = append(lines, closeStyles(line.String(), active))
+			line.Reset()
+			line.WriteString(active)
+			lineLen = 0
+		}
+		if wordLen > width {
+			parts := breakStyled(active+word, width)
+			for _, part := range parts[:len(parts)-1] {
+				if lineLen > 0 {
+					lines = append(lines, closeStyles(line.String(), active))
+					line.Reset()
+					lineLen = 0
+				}
+				lines = append(lines, part)
+			}
+			line.Reset()
+			line.WriteString(parts[len(parts)-1])
+			lineLen = visibleLen(parts[len(parts)-1])
+			active = activeStyles(active, word)
+			continue
+		}
+		if lineLen > 0 {
+			line.WriteByte(' ')
+			lineLen++
+		}
+		line.WriteString(word)
+		lineLen += wordLen
+		active = activeStyles(active, word)
+	}
+	return append(lines, closeStyles(line.String(), active))
+}
+
+// breakStyled splits s into pieces of at most width visible runes without
+// cutting through escape codes.
+func breakStyled(s string, width int) []string {
+	if width < 1 || visibleLen(s) <= width {
+		return []string{s}
+	}
+	var parts []string
+	var part strings.Builder
+	n := 0
+	active := ""
+	inEscape := false
+	var escape strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '\033':
+			inEscape = true
+			escape.Reset()
+			escape.WriteRune(c)
+		case inEscape:
+			escape.WriteRune(c)
+			if c >= '@' && c <= '~' && c != '[' {
+				inEscape = false
+				part.WriteString(escape.String())
+				active = activeStyles(active, escape.String())
+			}
+		default:
+			if n == width {
+				parts = append(parts, closeStyles(part.String(), active))
+				part.Reset()
+				part.WriteString(active)
+				n = 0
+			}
+			part.WriteRune(c)
+			n++
+		}
+	}
+	return append(parts, part.String())
+}
+
+// activeStyles returns the styles still in effect after printing s when
+// active were in effect before it.
+func activeStyles(active, s string) string {
+	for {
+		i := strings.Index(s, "\033[")
+		if i < 0 {
+			return active
+		}
+		end := strings.IndexFunc(s[i+2:], func(c rune) bool { return c >= '@' && c <= '~' })
+		if end < 0 {
+			return active
+		}
+		code := s[i : i+2+end+1]
+		if code == ansiReset {
+			active = ""
+		} else {
+			active += code
+		}
+		s = s[i+len(code):]
+	}
+}
+
+func closeStyles(line, active string) string {
+	if active == "" {
+		return line
+	}
+	return line + ansiReset
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package main
+
+import "testing"
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts renderOptions
+		want string
+	}{
+		{
+			name: "plain text",
+			src:  "hello   world",
+			want: "hello world",
+		},
+		{
+			name: "entities",
+			src:  "<p>Fish &amp; chips &lt;3 &quot;caf&eacute;&quot; &#8212; &#x263A;</p>",
+			want: "Fish & chips <3 \"café\" — ☺",
+		},
+		{
+			name: "paragraphs",
+			src:  "<p>one</p><p>two</p>",
+			want: "one\n\ntwo",
+		},
+		{
+			name: "unordered list",
+			src:  "<p>Items:</p><ul><li>first</li><li>second</li></ul>",
+			want: "Items:\n\n• first\n• second",
+		},
+		{
+			name: "ordered list with start",
+			src:  `<ol start="3"><li>three</li><li>four</li></ol>`,
+			want: "3. three\n4. four",
+		},
+		{
+			name: "nested list",
+			src:  "<ul><li>outer<ul><li>inner</li></ul></li></ul>",
+			want: "• outer\n  • inner",
+		},
+		{
+			name: "link becomes footnote",
+			src:  `<p>Read <a href="https://example.com/post">the post</a>.</p>`,
+			want: "Read the post[1].\n\n[1] https://example.com/post",
+		},
+		{
+			name: "link with its URL as text",
+			src:  `<a href="https://example.com">https://example.com</a>`,
+			want: "https://example.com",
+		},
+		{
+			name: "javascript and anchor links are dropped",
+			src:  `<a href="javascript:alert(1)">click</a> <a href="#top">top</a>`,
+			want: "click top",
+		},
+		{
+			name: "pre keeps layout",
+			src:  "<p>Code:</p><pre>if x {\n    y()\n}</pre>",
+			want: "Code:\n\n    if x {\n        y()\n    }",
+		},
+		{
+			name: "inline code",
+			src:  "<p>Run <code>go test</code> now</p>",
+			want: "Run `go test` now",
+		},
+		{
+			name: "scripts are dropped",
+			src:  "<p>a</p><script>alert(1)</script><style>p {}</style><p>b</p>",
+			want: "a\n\nb",
+		},
+		{
+			name: "image alt text",
+			src:  `<img src="x.png" alt="A cat"><img src="y.png">`,
+			want: "[image: A cat][image]",
+		},
+		{
+			name: "wraps at width",
+			src:  "<p>one two three four</p>",
+			opts: renderOptions{width: 10},
+			want: "one two\nthree four",
+		},
+		{
+			name: "strips escape sequences",
+			src:  "a\x1b]0;evil\x07b",
+			want: "a]0;evilb",
+		},
+		{
+			name: "strips C0 and C1 controls",
+			src:  "<p>a\x00b\x08c\x7fd\u009be\u0085f</p>",
+			want: "abcdef",
+		},
+		{
+			name: "strips controls in pre",
+			src:  "<pre>a\x1b[2Jb\tc</pre>",
+			want: "    a[2Jb\tc",
+		},
+		{
+			name: "strips controls in links",
+			src:  "<a href=\"https://example.com/\x1b[31m\">x</a>",
+			want: "x[1]\n\n[1] https://example.com/[31m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderHTML(tt.src, tt.opts); got != tt.want {
+				t.Errorf("renderHTML(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripControl(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"keeps\nnewlines\tand tabs", "keeps\nnewlines\tand tabs"},
+		{"a\x1b]0;evil\x07b", "a]0;evilb"},
+		{"a\rb\x00c", "abc"},
+		{"c1\u009b31m", "c131m"},
+		{"ünïcödé ☺", "ünïcödé ☺"},
+	}
+	for _, tt := range tests {
+		if got := stripControl(tt.in); got != tt.want {
+			t.Errorf("stripControl(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Posts are pruned by age and by count per feed. The default policy is kept
+// in the database, so everyone pruning it uses the same one, and feeds can
+// override it; 0 keeps posts forever.
+// Starred posts are never pruned. Pruned posts' URLs are remembered so that
+// scrapeFeeds doesn't fetch them again while they are still in the feed, and
+// forgotten by the next prune once a fetch no longer finds them.
+
+func setRetentionFlags(fs *flag.FlagSet) {
+	fs.Int("days", 0, "prune posts published more than this many days ago, 0 to keep them forever")
+	fs.Int("posts", 0, "keep at most this many posts per feed, 0 for no limit")
+	fs.Bool("default", false, "remove the feed's own policy so that it uses the default")
+}
+
+// handleRetention shows the retention policy, or changes the default policy
+// (admins only) or a feed's policy (its owner or an admin) when given flags.
+func handleRetention(s *state, cmd command, user database.User) error {
+	changing := cmd.flagPassed("days") || cmd.flagPassed("posts") || cmd.flagBool("default")
+	if cmd.flagInt("days") < 0 || cmd.flagInt("posts") < 0 {
+		return fmt.Errorf("--days and --posts must not be negative")
+	}
+	if len(cmd.args) == 0 {
+		if cmd.flagBool("default") {
+			return fmt.Errorf("--default needs a feed URL")
+		}
+		if changing {
+			if user.Role != roleAdmin {
+				return fmt.Errorf("only an admin can change the default retention policy")
+			}
+			settings, err := s.db.GetRetentionSettings(context.Background())
+			if err != nil {
+				return fmt.Errorf("error getting retention policy: %v", err)
+			}
+			params := database.SetRetentionSettingsParams{
+				KeepDays:  settings.KeepDays,
+				KeepPosts: settings.KeepPosts,
+				UpdatedAt: time.Now(),
+			}
+			if cmd.flagPassed("days") {
+				params.KeepDays = int32(cmd.flagInt("days"))
+			}
+			if cmd.flagPassed("posts") {
+				params.KeepPosts = int32(cmd.flagInt("posts"))
+			}
+			if err = s.db.SetRetentionSettings(context.Background(), params); err != nil {
+				return fmt.Errorf("error setting retention policy: %v", err)
+			}
+		}
+		return printRetention(s)
+	}
+
+	feed, err := s.db.GetFeedByURL(context.Background(), cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("error getting feed: %v", err)
+	}
+	if !changing {
+		return fmt.Errorf("nothing to change, give --days, --posts or --default")
+	}
+	if !canManageFeed(user, feed) {
+		return fmt.Errorf("only the feed's owner or an admin can change its retention policy")
+	}
+	if cmd.flagBool("default") {
+		if cmd.flagPassed("days") || cmd.flagPassed("posts") {
+			return fmt.Errorf("--default cannot be combined with --days or --posts")
+		}
+		if _, err = s.db.DeleteFeedRetention(context.Background(), feed.ID); err != nil {
+			return fmt.Errorf("error removing retention policy: %v", err)
+		}
+		return printRetention(s)
+	}
+
+	params := database.SetFeedRetentionParams{
+		FeedID:    feed.ID,
+		UpdatedAt: time.Now(),
+	}
+	overrides, err := s.db.GetFeedRetentions(context.Background())
+	if err != nil {
+		return fmt.Errorf("error getting retention policies: %v", err)
+	}
+	for _, override := range overrides {
+		if override.FeedID == feed.ID {
+			params.KeepDays = override.KeepDays
+			params.KeepPosts = override.KeepPosts
+		}
+	}
+	if cmd.flagPassed("days") {
+		params.KeepDays = sql.NullInt32{Int32: int32(cmd.flagInt("days")), Valid: true}
+	}
+	if cmd.flagPassed("posts") {
+		params.KeepPosts = sql.NullInt32{Int32: int32(cmd.flagInt("posts")), Valid: true}
+	}
+	if err = s.db.SetFeedRetention(context.Background(), params); err != nil {
+		return fmt.Errorf("error setting retention policy: %v", err)
+	}
+	return printRetention(s)
+}
+
+func printRetention(s *state) error {
+	settings, err := s.db.GetRetentionSettings(context.Background())
+	if err != nil {
+		return fmt.Errorf("error getting retention policy: %v", err)
+	}
+	overrides, err := s.db.GetFeedRetentions(context.Background())
+	if err != nil {
+		return fmt.Errorf("error getting retention policies: %v", err)
+	}
+	view := retentionView{
+		DefaultDays:  int(settings.KeepDays),
+		DefaultPosts: int(settings.KeepPosts),
+		Feeds:        make([]feedRetentionView, 0, len(overrides)),
+	}
+	for _, override := range overrides {
+		v := feedRetentionView{FeedName: override.FeedName, FeedUrl: override.FeedUrl}
+		if override.KeepDays.Valid {
+			v.Days = &override.KeepDays.Int32
+		}
+		if override.KeepPosts.Valid {
+			v.Posts = &override.KeepPosts.Int32
+		}
+		view.Feeds = append(view.Feeds, v)
+	}
+	return s.out.print(view, func() {
+		fmt.Printf("Default: %s\n", describeRetention(view.DefaultDays, view.DefaultPosts))
+		for _, feed := range view.Feeds {
+			days, posts := view.DefaultDays, view.DefaultPosts
+			if feed.Days != nil {
+				days = int(*feed.Days)
+			}
+			if feed.Posts != nil {
+				posts = int(*feed.Posts)
+			}
+			fmt.Printf("* %s (%s): %s\n", feed.FeedName, feed.FeedUrl, describeRetention(days, posts))
+		}
+	})
+}
+
+func describeRetention(days, posts int) string {
+	var limits []string
+	if days > 0 {
+		limits = append(limits, fmt.Sprintf("for %d days", days))
+	}
+	if posts > 0 {
+		limits = append(limits, fmt.Sprintf("at most %d per feed", posts))
+	}
+	if len(limits) == 0 {
+		return "keep posts forever"
+	}
+	return "keep posts " + strings.Join(limits, " and ")
+}
+
+// prune deletes the posts the retention policies no longer keep, or with
+// dryRun only reports them.
+func prune(ctx context.Context, s *state, dryRun bool) (pruneView, error) {
+	rows, err := s.db.GetPrunablePosts(ctx, time.Now())
+	if err != nil {
+		return pruneView{}, fmt.Errorf("error getting posts to prune: %v", err)
+	}
+	view := pruneView{DryRun: dryRun, Feeds: []pruneFeedView{}}
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+		// Rows come grouped by feed.
+		if n := len(view.Feeds); n == 0 || view.Feeds[n-1].ID != row.FeedID {
+			view.Feeds = append(view.Feeds, pruneFeedView{ID: row.FeedID, Name: row.FeedName, Url: row.FeedUrl})
+		}
+		view.Feeds[len(view.Feeds)-1].Posts++
+	}
+	view.Posts = int64(len(ids))
+	if !dryRun && len(ids) > 0 {
+		// Posts starred since they were listed are kept, so report what
+		// was actually deleted.
+		view.Posts, err = s.db.PrunePosts(ctx, database.PrunePostsParams{
+			PrunedAt: time.Now(),
+			Ids:      ids,
+		})
+		if err != nil {
+			return pruneView{}, fmt.Errorf("error pruning posts: %v", err)
+		}
+	}
+	if !dryRun {
+		if _, err = s.db.ExpirePrunedPosts(ctx); err != nil {
+			return pruneView{}, fmt.Errorf("error expiring pruned posts: %v", err)
+		}
+	}
+	return view, nil
+}
+
+func handlePrune(s *state, cmd command, _ database.User) error {
+	view, err := prune(context.Background(), s, cmd.flagBool("dry-run"))
+	if err != nil {
+		return err
+	}
+	return s.out.print(view, func() {
+		verb := "Pruned"
+		if view.DryRun {
+			verb = "Would prune"
+		}
+		for _, feed := range view.Feeds {
+			fmt.Printf("* %s (%s): %d posts\n", feed.Name, feed.Url, feed.Posts)
+		}
+		fmt.Printf("%s %d posts\n", verb, view.Posts)
+	})
+}
+
+// pruneBetweenCycles runs prune for agg, logging instead of failing so that
+// fetching carries on.
+func pruneBetweenCycles(s *state) {
+	view, err := prune(context.Background(), s, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error pruning posts: %v\n", err)
+		return
+	}
+	if view.Posts > 0 && s.out.isText() {
+		fmt.Printf("Pruned %d posts\n", view.Posts)
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Filter rules let users deal with noisy feeds. A rule matches a keyword or
+// regular expression against a field of a post, optionally only in one feed
+// or folder, and then hides, marks read, stars or tags the post. scrapeFeeds
+// applies rules to new posts, and "gator applyrules" applies them to posts
+// fetched before the rule was added.
+
+const (
+	ruleFieldAny         = "any"
+	ruleFieldTitle       = "title"
+	ruleFieldDescription = "description"
+	ruleFieldAuthor      = "author"
+
+	ruleMatchKeyword = "keyword"
+	ruleMatchRegex   = "regex"
+
+	ruleActionHide = "hide"
+	ruleActionRead = "read"
+	ruleActionStar = "star"
+	ruleActionTag  = "tag"
+)
+
+var (
+	ruleFields  = []string{ruleFieldAny, ruleFieldTitle, ruleFieldDescription, ruleFieldAuthor}
+	ruleActions = []string{ruleActionHide, ruleActionRead, ruleActionStar, ruleActionTag}
+)
+
+// filterRule is a rule ready to match posts. Keywords match anywhere in the
+// field, ignoring case; regular expressions use Go's syntax, so (?i) makes
+// them ignore case.
+type filterRule struct {
+	database.FilterRule
+	re *regexp.Regexp
+}
+
+func compileFilterRule(rule database.FilterRule) (filterRule, error) {
+	compiled := filterRule{FilterRule: rule}
+	if rule.MatchType == ruleMatchRegex {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return filterRule{}, fmt.Errorf("invalid regular expression %q: %v", rule.Pattern, err)
+		}
+		compiled.re = re
+	}
+	return compiled, nil
+}
+
+// matches reports whether the rule matches a post. description is the post's
+// rendered text rather than its HTML, so that markup such as tag names and
+// link URLs doesn't match.
+func (rule filterRule) matches(title, description, author string) bool {
+	var fields []string
+	switch rule.Field {
+	case ruleFieldTitle:
+		fields = []string{title}
+	case ruleFieldDescription:
+		fields = []string{description}
+	case ruleFieldAuthor:
+		fields = []string{author}
+	default:
+		fields = []string{title, description, author}
+	}
+	for _, field := range fields {
+		if rule.re != nil && rule.re.MatchString(field) {
+			return true
+		}
+		if rule.re == nil && strings.Contains(strings.ToLower(field), strings.ToLower(rule.Pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply takes the rule's action on a post for the rule's owner.
+func (rule filterRule) apply(ctx context.Context, db *database.Queries, postID uuid.UUID) error {
+	switch rule.Action {
+	case ruleActionHide:
+		err := db.HidePost(ctx, database.HidePostParams{
+			UserID:   rule.UserID,
+			PostID:   postID,
+			RuleID:   rule.ID,
+			HiddenAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("error hiding post: %v", err)
+		}
+	case ruleActionRead:
+		err := db.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: rule.UserID,
+			PostID: postID,
+			ReadAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("error marking post read: %v", err)
+		}
+	case ruleActionStar:
+		err := db.StarPost(ctx, database.StarPostParams{
+			UserID:    rule.UserID,
+			PostID:    postID,
+			StarredAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("error starring post: %v", err)
+		}
+	case ruleActionTag:
+		user := database.User{ID: rule.UserID}
+		if err := tagPost(ctx, db, user, postID, []string{rule.Tag}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// feedFilterRules returns the rules of every user following a feed that
+// apply to its posts. Rules that fail to compile are logged and skipped.
+func feedFilterRules(ctx context.Context, db *database.Queries, feedID uuid.UUID) ([]filterRule, error) {
+	rows, err := db.GetFilterRulesForFeed(ctx, feedID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]filterRule, 0, len(rows))
+	for _, row := range rows {
+		rule, err := compileFilterRule(row)
+		if err != nil {
+			log.Printf("skipping filter rule %s: %v", row.Name, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// applyFilterRules runs rules on a newly fetched post, logging errors so
+// that one bad rule doesn't stop a scrape.
+func applyFilterRules(ctx context.Context, db *database.Queries, rules []filterRule, post database.Post) {
+	for _, rule := range rules {
+		if !rule.matches(post.Title, postContent(post.ContentText, post.Description), post.Author) {
+			continue
+		}
+		if err := rule.apply(ctx, db, post.ID); err != nil {
+			log.Printf("error applying filter rule %s: %v", rule.Name, err)
+		}
+	}
+}
+
+func setAddRuleFlags(fs *flag.FlagSet) {
+	fs.String("field", ruleFieldAny, "field to match: "+strings.Join(ruleFields, ", "))
+	fs.Bool("regex", false, "match a regular expression instead of a keyword")
+	fs.String("feed", "", "only match posts from the feed with this URL")
+	fs.String("folder", "", "only match posts from feeds in this folder")
+	fs.String("action", ruleActionHide, "what to do with matching posts: "+strings.Join(ruleActions, ", "))
+	fs.String("tag", "", "tag to add when the action is tag")
+}
+
+func handleAddRule(s *state, cmd command, user database.User) error {
+	params := database.CreateFilterRuleParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(cmd.args[0]),
+		Field:     cmd.flagString("field"),
+		MatchType: ruleMatchKeyword,
+		Pattern:   cmd.args[1],
+		Action:    cmd.flagString("action"),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if params.Name == "" {
+		return fmt.Errorf("rule name cannot be empty")
+	}
+	if params.Pattern == "" {
+		return fmt.Errorf("pattern cannot be empty")
+	}
+	if !slices.Contains(ruleFields, params.Field) {
+		return fmt.Errorf("unknown field %q: must be one of %s", params.Field, strings.Join(ruleFields, ", "))
+	}
+	if !slices.Contains(ruleActions, params.Action) {
+		return fmt.Errorf("unknown action %q: must be one of %s", params.Action, strings.Join(ruleActions, ", "))
+	}
+	if cmd.flagBool("regex") {
+		params.MatchType = ruleMatchRegex
+	}
+	if params.Action == ruleActionTag {
+		if !cmd.flagPassed("tag") {
+			return fmt.Errorf("--tag is required with --action tag")
+		}
+		tag, err := normalizeTag(cmd.flagString("tag"))
+		if err != nil {
+			return err
+		}
+		params.Tag = tag
+	} else if cmd.flagPassed("tag") {
+		return fmt.Errorf("--tag can only be used with --action tag")
+	}
+	if feedURL := cmd.flagString("feed"); feedURL != "" {
+		feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+		if err != nil {
+			return fmt.Errorf("error getting feed: %v", err)
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if cmd.flagPassed("folder") {
+		params.Folder = sql.NullString{String: normalizeFolder(cmd.flagString("folder")), Valid: true}
+	}
+	if _, err := compileFilterRule(database.FilterRule{MatchType: params.MatchType, Pattern: params.Pattern}); err != nil {
+		return err
+	}
+
+	rule, err := s.db.CreateFilterRule(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error creating rule (is the name %q already used?): %v", params.Name, err)
+	}
+	view := newRuleView(rule, cmd.flagString("feed"))
+	return s.out.print(view, func() {
+		fmt.Printf("Added rule %s\n", view.describe())
+		fmt.Println("It applies to new posts; run \"gator applyrules " + rule.Name + "\" for posts already fetched.")
+	})
+}
+
+func handleRules(s *state, _ command, user database.User) error {
+	rows, err := s.db.GetFilterRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting rules: %v", err)
+	}
+	views := make([]ruleView, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, newRuleView(ruleFromRow(row), row.FeedUrl.String))
+	}
+	return s.out.print(views, func() {
+		if len(views) == 0 {
+			fmt.Println("No rules")
+		}
+		for _, rule := range views {
+			fmt.Printf("* %s\n", rule.describe())
+		}
+	})
+}
+
+// handleRmRule deletes a rule and lists the posts it hid, which show up
+// again unless another rule hides them too.
+func handleRmRule(s *state, cmd command, user database.User) error {
+	hidden, err := s.db.GetPostsHiddenOnlyByFilterRule(context.Background(), database.GetPostsHiddenOnlyByFilterRuleParams{
+		UserID: user.ID,
+		Name:   cmd.args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("error getting hidden posts: %v", err)
+	}
+	n, err := s.db.DeleteFilterRule(context.Background(), database.DeleteFilterRuleParams{
+		UserID: user.ID,
+		Name:   cmd.args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting rule: %v", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("no rule named %q", cmd.args[0])
+	}
+	view := removedRuleView{Rule: cmd.args[0], Unhidden: make([]unhiddenPostView, 0, len(hidden))}
+	for _, post := range hidden {
+		view.Unhidden = append(view.Unhidden, unhiddenPostView{ID: post.ID, Title: post.Title, Url: post.Url})
+	}
+	return s.out.print(view, func() {
+		fmt.Printf("Removed rule %s\n", view.Rule)
+		if len(view.Unhidden) > 0 {
+			fmt.Printf("%d posts are no longer hidden:\n", len(view.Unhidden))
+		}
+		for _, post := range view.Unhidden {
+			fmt.Printf("* %s (%s)\n", post.Title, post.Url)
+		}
+	})
+}
+
+func setApplyRulesFlags(fs *flag.FlagSet) {
+	fs.Bool("dry-run", false, "only report how many posts each rule matches")
+}
+
+// handleApplyRules runs the user's rules, or the named ones, on every post
+// in the feeds they follow.
+func handleApplyRules(s *state, cmd command, user database.User) error {
+	rows, err := s.db.GetFilterRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting rules: %v", err)
+	}
+	var rules []filterRule
+	for _, row := range rows {
+		if len(cmd.args) > 0 && !slices.Contains(cmd.args, row.Name) {
+			continue
+		}
+		rule, err := compileFilterRule(ruleFromRow(row))
+		if err != nil {
+			return fmt.Errorf("rule %s: %v", row.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	for _, name := range cmd.args {
+		if !slices.ContainsFunc(rules, func(rule filterRule) bool { return rule.Name == name }) {
+			return fmt.Errorf("no rule named %q", name)
+		}
+	}
+
+	view := applyRulesView{DryRun: cmd.flagBool("dry-run"), Rules: []ruleRunView{}}
+	for _, rule := range rules {
+		posts, err := s.db.GetPostsForFilterRule(context.Background(), database.GetPostsForFilterRuleParams{
+			UserID: user.ID,
+			FeedID: rule.FeedID,
+			Folder: rule.Folder,
+		})
+		if err != nil {
+			return fmt.Errorf("error getting posts for rule %s: %v", rule.Name, err)
+		}
+		run := ruleRunView{Rule: rule.Name, Action: rule.Action}
+		for _, post := range posts {
+			if !rule.matches(post.Title, postContent(post.ContentText, post.Description), post.Author) {
+				continue
+			}
+			run.Matches++
+			if view.DryRun {
+				continue
+			}
+			if err := rule.apply(context.Background(), s.db, post.ID); err != nil {
+				return fmt.Errorf("rule %s: %v", rule.Name, err)
+			}
+		}
+		view.Rules = append(view.Rules, run)
+	}
+	return s.out.print(view, func() {
+		if len(view.Rules) == 0 {
+			fmt.Println("No rules")
+		}
+		verb := "Applied"
+		if view.DryRun {
+			verb = "Would apply"
+		}
+		for _, run := range view.Rules {
+			fmt.Printf("%s %s (%s) to %d posts\n", verb, run.Rule, run.Action, run.Matches)
+		}
+	})
+}
+
+func ruleFromRow(row database.GetFilterRulesForUserRow) database.FilterRule {
+	return database.FilterRule{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Name:      row.Name,
+		Field:     row.Field,
+		MatchType: row.MatchType,
+		Pattern:   row.Pattern,
+		FeedID:    row.FeedID,
+		Folder:    row.Folder,
+		Action:    row.Action,
+		Tag:       row.Tag,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+func completeRuleNames(s *state) ([]candidate, error) {
+	user, err := currentUser(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.db.GetFilterRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidate, 0, len(rules))
+	for _, rule := range rules {
+		candidates = append(candidates, candidate{value: rule.Name, description: rule.Pattern})
+	}
+	return candidates, nil
+}
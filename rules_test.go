@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/awbalessa/gator/internal/database"
+)
+
+func TestFilterRuleMatches(t *testing.T) {
+	const (
+		title       = "Sponsored: Try Our New Product"
+		description = `<p class="promo">Big savings on <a href="https://shop.example.com/">widgets</a></p>`
+		author      = "Jane Doe"
+	)
+	text := postContent("", description)
+	tests := []struct {
+		name      string
+		field     string
+		matchType string
+		pattern   string
+		want      bool
+	}{
+		{"keyword in title", ruleFieldTitle, ruleMatchKeyword, "sponsored", true},
+		{"keyword ignores case", ruleFieldTitle, ruleMatchKeyword, "NEW PRODUCT", true},
+		{"keyword not in title", ruleFieldTitle, ruleMatchKeyword, "widgets", false},
+		{"keyword in description", ruleFieldDescription, ruleMatchKeyword, "widgets", true},
+		{"keyword ignores tag names", ruleFieldDescription, ruleMatchKeyword, "<p", false},
+		{"keyword ignores attributes", ruleFieldAny, ruleMatchKeyword, "class", false},
+		{"keyword ignores markup", ruleFieldAny, ruleMatchKeyword, "href", false},
+		{"regex ignores markup", ruleFieldDescription, ruleMatchRegex, "<a ", false},
+		{"keyword in author", ruleFieldAuthor, ruleMatchKeyword, "jane", true},
+		{"keyword only checks author", ruleFieldAuthor, ruleMatchKeyword, "sponsored", false},
+		{"any field checks title", ruleFieldAny, ruleMatchKeyword, "sponsored", true},
+		{"any field checks description", ruleFieldAny, ruleMatchKeyword, "savings", true},
+		{"any field checks author", ruleFieldAny, ruleMatchKeyword, "doe", true},
+		{"any field without match", ruleFieldAny, ruleMatchKeyword, "rust", false},
+		{"regex anchored", ruleFieldTitle, ruleMatchRegex, "^Sponsored:", true},
+		{"regex anchor misses", ruleFieldTitle, ruleMatchRegex, "^Product", false},
+		{"regex is case sensitive", ruleFieldTitle, ruleMatchRegex, "sponsored", false},
+		{"regex with (?i)", ruleFieldTitle, ruleMatchRegex, "(?i)sponsored", true},
+		{"regex alternation", ruleFieldAuthor, ruleMatchRegex, "John|Jane", true},
+		{"regex on any field", ruleFieldAny, ruleMatchRegex, `wid\w+s`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := compileFilterRule(database.FilterRule{
+				Field:     tt.field,
+				MatchType: tt.matchType,
+				Pattern:   tt.pattern,
+			})
+			if err != nil {
+				t.Fatalf("compileFilterRule(%q) returned error: %v", tt.pattern, err)
+			}
+			if got := rule.matches(title, text, author); got != tt.want {
+				t.Errorf("matches with %s %s %q = %v, want %v", tt.field, tt.matchType, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileFilterRuleInvalidRegex(t *testing.T) {
+	_, err := compileFilterRule(database.FilterRule{
+		Field:     ruleFieldTitle,
+		MatchType: ruleMatchRegex,
+		Pattern:   "(unclosed",
+	})
+	if err == nil {
+		t.Error("compileFilterRule with an invalid regular expression returned no error")
+	}
+}
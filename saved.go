@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Saved searches are named browse filters: a search query, a feed and a
+// folder, any of which may be left out. They show up after the followed
+// feeds in "gator following" with their unread counts, and "gator browse
+// --saved <name>" browses them like a feed.
+
+var errNoSavedSearch = errors.New("saved search not found")
+
+func setSaveSearchFlags(fs *flag.FlagSet) {
+	fs.String("feed", "", "only match posts from the feed with this URL")
+	fs.String("folder", "", "only match posts from feeds in this folder or its subfolders")
+}
+
+func handleSaveSearch(s *state, cmd command, user database.User) error {
+	params := database.CreateSavedSearchParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(cmd.args[0]),
+		Query:     strings.TrimSpace(strings.Join(cmd.args[1:], " ")),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if params.Name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if params.Query != "" {
+		query, err := buildTSQuery(params.Query)
+		if err != nil {
+			return err
+		}
+		params.TsQuery = sql.NullString{String: query, Valid: true}
+	}
+	feedURL := cmd.flagString("feed")
+	if feedURL != "" {
+		feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+		if err != nil {
+			return fmt.Errorf("error getting feed: %v", err)
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if folder := normalizeFolder(cmd.flagString("folder")); folder != "" {
+		params.Folder = sql.NullString{String: folder, Valid: true}
+	}
+	if !params.TsQuery.Valid && !params.FeedID.Valid && !params.Folder.Valid {
+		return fmt.Errorf("give a search query, --feed or --folder")
+	}
+
+	saved, err := s.db.CreateSavedSearch(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error saving search (is the name %q already used?): %v", params.Name, err)
+	}
+	view := newSavedSearchView(saved, feedURL, 0)
+	return s.out.print(view, func() {
+		fmt.Printf("Saved search %s, browse it with \"gator browse --saved %s\"\n", view.describe(), saved.Name)
+	})
+}
+
+func getSavedSearches(ctx context.Context, db *database.Queries, user database.User) ([]savedSearchView, error) {
+	rows, err := db.GetSavedSearchesForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting saved searches: %v", err)
+	}
+	views := make([]savedSearchView, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, newSavedSearchView(savedSearchFromRow(row), row.FeedUrl.String, row.UnreadCount))
+	}
+	return views, nil
+}
+
+func handleSearches(s *state, _ command, user database.User) error {
+	views, err := getSavedSearches(context.Background(), s.db, user)
+	if err != nil {
+		return err
+	}
+	return s.out.print(views, func() {
+		if len(views) == 0 {
+			fmt.Println("No saved searches")
+		}
+		for _, search := range views {
+			fmt.Printf("* %s (%d unread)\n", search.describe(), search.UnreadCount)
+		}
+	})
+}
+
+func handleRmSearch(s *state, cmd command, user database.User) error {
+	n, err := s.db.DeleteSavedSearch(context.Background(), database.DeleteSavedSearchParams{
+		UserID: user.ID,
+		Name:   cmd.args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting saved search: %v", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("no saved search named %q", cmd.args[0])
+	}
+	msg := fmt.Sprintf("Removed saved search %s", cmd.args[0])
+	return s.out.print(messageView{Message: msg}, func() {
+		fmt.Println(msg)
+	})
+}
+
+// applySavedSearch narrows f down to the posts matching the user's saved
+// search called name. It returns errNoSavedSearch if there is none.
+func applySavedSearch(ctx context.Context, db *database.Queries, user database.User, name string, f *postFilter) error {
+	saved, err := db.GetSavedSearch(ctx, database.GetSavedSearchParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errNoSavedSearch
+	} else if err != nil {
+		return fmt.Errorf("error getting saved search: %v", err)
+	}
+	f.tsQuery = saved.TsQuery
+	f.feedID = saved.FeedID
+	f.folder = saved.Folder
+	return nil
+}
+
+func savedSearchFromRow(row database.GetSavedSearchesForUserRow) database.SavedSearch {
+	return database.SavedSearch{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Name:      row.Name,
+		Query:     row.Query,
+		TsQuery:   row.TsQuery,
+		FeedID:    row.FeedID,
+		Folder:    row.Folder,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+func completeSavedSearches(s *state) ([]candidate, error) {
+	user, err := currentUser(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+	searches, err := s.db.GetSavedSearchesForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidate, 0, len(searches))
+	for _, search := range searches {
+		candidates = append(candidates, candidate{value: search.Name, description: search.Query})
+	}
+	return candidates, nil
+}
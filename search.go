@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+const (
+	highlightStart = "[["
+	highlightStop  = "]]"
+)
+
+// buildTSQuery translates a search string into to_tsquery syntax. Bare words
+// are ANDed together, "quoted phrases" must appear in order, a trailing *
+// makes a prefix match, a leading - excludes a term and OR between two terms
+// matches either of them.
+func buildTSQuery(input string) (string, error) {
+	var b strings.Builder
+	pendingOr := false
+	for _, token := range tokenizeQuery(input) {
+		if token == "OR" {
+			pendingOr = b.Len() > 0
+			continue
+		}
+
+		negate := false
+		if strings.HasPrefix(token, "-") && len(token) > 1 {
+			negate = true
+			token = token[1:]
+		}
+
+		var term string
+		if strings.HasPrefix(token, `"`) {
+			words := strings.Fields(strings.Trim(token, `"`))
+			if len(words) == 0 {
+				continue
+			}
+			for i := range words {
+				words[i] = quoteLexeme(words[i])
+			}
+			term = "(" + strings.Join(words, " <-> ") + ")"
+		} else {
+			prefix := strings.HasSuffix(token, "*")
+			token = strings.TrimRight(token, "*")
+			if strings.TrimFunc(token, isQuerySeparator) == "" {
+				continue
+			}
+			term = quoteLexeme(token)
+			if prefix {
+				term += ":*"
+			}
+		}
+		if negate {
+			term = "!" + term
+		}
+
+		if b.Len() > 0 {
+			if pendingOr {
+				b.WriteString(" | ")
+			} else {
+				b.WriteString(" & ")
+			}
+		}
+		b.WriteString(term)
+		pendingOr = false
+	}
+
+	if b.Len() == 0 {
+		return "", fmt.Errorf("search query is empty")
+	}
+	return b.String(), nil
+}
+
+// tokenizeQuery splits a search string on whitespace, keeping quoted phrases
+// (including their quotes) together as a single token.
+func tokenizeQuery(input string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range input {
+		switch {
+		case r == '"':
+			current.WriteRune(r)
+			if inQuotes {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// quoteLexeme wraps a single term in tsquery quotes so punctuation inside it
+// is handed to the text search parser instead of the tsquery parser.
+func quoteLexeme(word string) string {
+	word = strings.ReplaceAll(word, `\`, `\\`)
+	word = strings.ReplaceAll(word, `'`, `''`)
+	return "'" + word + "'"
+}
+
+func isQuerySeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// highlight replaces the ts_headline markers with bold text on a terminal and
+// with asterisks everywhere else.
+func highlight(text string) string {
+	start, stop := "*", "*"
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		start, stop = "\033[1m", "\033[0m"
+	}
+	text = strings.ReplaceAll(text, highlightStart, start)
+	return strings.ReplaceAll(text, highlightStop, stop)
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "go", want: "'go'"},
+		{input: "go  generics", want: "'go' & 'generics'"},
+		{input: `"type parameters"`, want: "('type' <-> 'parameters')"},
+		{input: "gener*", want: "'gener':*"},
+		{input: "go -java", want: "'go' & !'java'"},
+		{input: "go OR rust", want: "'go' | 'rust'"},
+		{input: "OR go", want: "'go'"},
+		{input: `-"error handling" go`, want: "!('error' <-> 'handling') & 'go'"},
+		{input: "it's", want: "'it''s'"},
+		{input: `back\slash`, want: `'back\\slash'`},
+		{input: "c++ go", want: "'c++' & 'go'"},
+		{input: "", wantErr: true},
+		{input: `   "" *`, wantErr: true},
+		{input: "-- !!", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := buildTSQuery(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("buildTSQuery(%q) = %q, want an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("buildTSQuery(%q) returned error: %v", tt.input, err)
+		} else if got != tt.want {
+			t.Errorf("buildTSQuery(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,794 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultServeAddr = "localhost:8080"
+
+	apiDefaultLimit = 20
+	apiMaxLimit     = 200
+
+	// apiMaxBodyBytes bounds API request bodies, which are small JSON
+	// objects.
+	apiMaxBodyBytes = 1 << 20
+)
+
+// server exposes the database as a JSON API so other programs, such as
+// dashboards and chat bots, can use gator without linking against it, and
+// as a web reader for people who prefer a browser. API requests act on
+// behalf of the owner of the bearer token they send; the web UI uses a
+// session cookie set when signing in. The API ignores the cookie, so another
+// site can't make a signed in browser call it.
+type server struct {
+	s     *state
+	pages map[string]*template.Template
+}
+
+// postPageView is one page of posts returned by the API. Next is the cursor
+// for the following page and is empty on the last one.
+type postPageView struct {
+	Posts []postView `json:"posts"`
+	Next  string     `json:"next,omitempty"`
+}
+
+type errorView struct {
+	Error string `json:"error"`
+}
+
+func setServeFlags(fs *flag.FlagSet) {
+	fs.String("addr", defaultServeAddr, "address to listen on")
+}
+
+func handleServe(s *state, cmd command) error {
+	pages, err := parsePages()
+	if err != nil {
+		return err
+	}
+	srv := &server{s: s, pages: pages}
+	httpServer := &http.Server{
+		Addr:              cmd.flagString("addr"),
+		Handler:           srv.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if s.out.isText() {
+		fmt.Printf("Serving gator on http://%s (API under /api)\n", httpServer.Addr)
+	}
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving API: %v", err)
+	}
+	return nil
+}
+
+func (srv *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/users", srv.handleUsers)
+	mux.HandleFunc("POST /api/users", srv.withAdmin(srv.handleCreateUser))
+	mux.HandleFunc("GET /api/users/{name}", srv.handleUser)
+	mux.HandleFunc("PATCH /api/users/{name}", srv.withAdmin(srv.handleRenameUser))
+	mux.HandleFunc("DELETE /api/users/{name}", srv.withAdmin(srv.handleDeleteUser))
+	mux.HandleFunc("GET /api/feeds", srv.handleFeeds)
+	mux.HandleFunc("POST /api/feeds", srv.withUser(srv.handleAddFeed))
+	mux.HandleFunc("GET /api/follows", srv.withUser(srv.handleFollows))
+	mux.HandleFunc("POST /api/follows", srv.withUser(srv.handleFollow))
+	mux.HandleFunc("DELETE /api/follows/{feedID}", srv.withUser(srv.handleUnfollow))
+	mux.HandleFunc("GET /api/posts", srv.withUser(srv.handlePosts))
+	mux.HandleFunc("PUT /api/posts/{id}/read", srv.withUser(srv.handleMarkRead))
+	mux.HandleFunc("DELETE /api/posts/{id}/read", srv.withUser(srv.handleMarkUnread))
+	mux.HandleFunc("PUT /api/posts/{id}/star", srv.withUser(srv.handleStar))
+	mux.HandleFunc("DELETE /api/posts/{id}/star", srv.withUser(srv.handleUnstar))
+	mux.HandleFunc("PUT /api/posts/{id}/tags/{tag}", srv.withUser(srv.handleTagPost))
+	mux.HandleFunc("DELETE /api/posts/{id}/tags/{tag}", srv.withUser(srv.handleUntagPost))
+	mux.HandleFunc("GET /api/starred", srv.withUser(srv.handleStarred))
+	mux.HandleFunc("GET /api/tags", srv.withUser(srv.handleTags))
+	mux.HandleFunc("GET /api/tags/{tag}", srv.withUser(srv.handleTagged))
+	mux.HandleFunc("GET /api/search", srv.withUser(srv.handleSearch))
+	mux.HandleFunc("GET /api/searches", srv.withUser(srv.handleSavedSearches))
+	mux.HandleFunc("/fever/", srv.handleFever)
+	mux.HandleFunc("GET /users/{name}/{file}", srv.handlePublished)
+	srv.webRoutes(mux)
+	return mux
+}
+
+var errNoUser = errors.New("no user given")
+
+// apiUser returns the owner of the API token in the request's
+// "Authorization: Bearer" header. An unknown token is sql.ErrNoRows.
+func (srv *server) apiUser(r *http.Request) (database.User, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return database.User{}, errNoUser
+	}
+	return userFromAPIToken(r.Context(), srv.s.db, strings.TrimSpace(token))
+}
+
+// withUser resolves the user an API request acts for before calling handler.
+func (srv *server) withUser(handler func(http.ResponseWriter, *http.Request, database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := srv.apiUser(r)
+		if errors.Is(err, errNoUser) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respondError(w, http.StatusUnauthorized, "authentication required, send an API token as \"Authorization: Bearer <token>\"")
+			return
+		} else if errors.Is(err, sql.ErrNoRows) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			respondError(w, http.StatusUnauthorized, "invalid API token")
+			return
+		} else if err != nil {
+			respondInternalError(w, "error getting user", err)
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+// withAdmin is withUser for requests only admins may make.
+func (srv *server) withAdmin(handler func(http.ResponseWriter, *http.Request, database.User)) http.HandlerFunc {
+	return srv.withUser(func(w http.ResponseWriter, r *http.Request, user database.User) {
+		if user.Role != roleAdmin {
+			respondError(w, http.StatusForbidden, "only admins can do this")
+			return
+		}
+		handler(w, r, user)
+	})
+}
+
+func (srv *server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := srv.s.db.GetUsers(r.Context())
+	if err != nil {
+		respondInternalError(w, "error getting users", err)
+		return
+	}
+	current := srv.currentName(r)
+	views := make([]userView, 0, len(users))
+	for _, user := range users {
+		views = append(views, newUserView(user, current))
+	}
+	respondJSON(w, http.StatusOK, views)
+}
+
+// currentName is the name of the user a request authenticates as, if any,
+// which user listings mark as current.
+func (srv *server) currentName(r *http.Request) string {
+	user, err := srv.apiUser(r)
+	if err != nil {
+		return ""
+	}
+	return user.Name
+}
+
+func (srv *server) handleUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := srv.pathUser(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, newUserView(user, srv.currentName(r)))
+}
+
+// handleCreateUser registers a user. Only admins can, so that a server
+// reachable by others doesn't let anyone sign up; the first user is
+// registered with "gator register".
+func (srv *server) handleCreateUser(w http.ResponseWriter, r *http.Request, admin database.User) {
+	var body struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if body.Name == "" {
+		respondError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if len(body.Password) < minPasswordLen {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("password must be at least %d characters", minPasswordLen))
+		return
+	}
+	if _, err := srv.s.db.GetUser(r.Context(), body.Name); err == nil {
+		respondError(w, http.StatusConflict, fmt.Sprintf("user %s already registered", body.Name))
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		respondInternalError(w, "error getting user", err)
+		return
+	}
+
+	hash, err := hashPassword(body.Password)
+	if err != nil {
+		respondInternalError(w, "error creating user", err)
+		return
+	}
+	user, err := srv.s.db.CreateUser(r.Context(), database.CreateUserParams{
+		ID:             uuid.New(),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		Name:           body.Name,
+		HashedPassword: hash,
+		Role:           roleUser,
+	})
+	if err != nil {
+		respondWriteError(w, err, "error creating user", fmt.Sprintf("user %s already registered", body.Name), "")
+		return
+	}
+	respondJSON(w, http.StatusCreated, newUserView(user, admin.Name))
+}
+
+// pathUser returns the user named in the request path, answering with 404
+// if there is none.
+func (srv *server) pathUser(w http.ResponseWriter, r *http.Request) (database.User, bool) {
+	user, err := srv.s.db.GetUser(r.Context(), r.PathValue("name"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, http.StatusNotFound, "user not found")
+		return database.User{}, false
+	} else if err != nil {
+		respondInternalError(w, "error getting user", err)
+		return database.User{}, false
+	}
+	return user, true
+}
+
+func (srv *server) handleRenameUser(w http.ResponseWriter, r *http.Request, admin database.User) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if body.Name == "" {
+		respondError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	user, ok := srv.pathUser(w, r)
+	if !ok {
+		return
+	}
+	err := srv.s.db.RenameUser(r.Context(), database.RenameUserParams{
+		ID:        user.ID,
+		Name:      body.Name,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		respondWriteError(w, err, "error renaming user", fmt.Sprintf("user %s already exists", body.Name), "")
+		return
+	}
+	user.Name = body.Name
+	respondJSON(w, http.StatusOK, newUserView(user, admin.Name))
+}
+
+// handleDeleteUser is "gator deluser" for the API. The transfer_to query
+// parameter names the user to give the deleted user's feeds to.
+func (srv *server) handleDeleteUser(w http.ResponseWriter, r *http.Request, admin database.User) {
+	user, ok := srv.pathUser(w, r)
+	if !ok {
+		return
+	}
+	if user.ID == admin.ID {
+		respondError(w, http.StatusConflict, "you cannot delete yourself, ask another admin")
+		return
+	}
+	var heir database.User
+	if name := r.URL.Query().Get("transfer_to"); name != "" {
+		var err error
+		heir, err = srv.s.db.GetUser(r.Context(), name)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(w, http.StatusNotFound, fmt.Sprintf("user %s not found", name))
+			return
+		} else if err != nil {
+			respondInternalError(w, "error getting user", err)
+			return
+		}
+		if heir.ID == user.ID {
+			respondError(w, http.StatusBadRequest, "cannot transfer feeds to the user being deleted")
+			return
+		}
+	}
+	if _, err := deleteUser(r.Context(), srv.s, user, heir); err != nil {
+		respondInternalError(w, "error deleting user", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *server) handleFeeds(w http.ResponseWriter, r *http.Request) {
+	feeds, err := srv.s.db.GetFeeds(r.Context())
+	if err != nil {
+		respondInternalError(w, "error getting feeds", err)
+		return
+	}
+	views := make([]feedView, 0, len(feeds))
+	for _, feed := range feeds {
+		owner, err := feedOwner(r.Context(), srv.s.db, feed)
+		if err != nil {
+			respondInternalError(w, "error getting feed owner", err)
+			return
+		}
+		views = append(views, newFeedView(feed, owner))
+	}
+	respondJSON(w, http.StatusOK, views)
+}
+
+func (srv *server) handleAddFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if body.Name == "" || body.Url == "" {
+		respondError(w, http.StatusBadRequest, "name and url are required")
+		return
+	}
+
+	feed, err := srv.s.db.CreateFeed(r.Context(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      body.Name,
+		Url:       body.Url,
+		AddedBy:   uuid.NullUUID{UUID: user.ID, Valid: true},
+	})
+	if err != nil {
+		respondWriteError(w, err, "error creating feed", fmt.Sprintf("a feed with URL %s already exists", body.Url), "")
+		return
+	}
+	_, err = srv.s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		respondWriteError(w, err, "error creating feed follow record", "already following this feed", "")
+		return
+	}
+	respondJSON(w, http.StatusCreated, newFeedView(feed, user.Name))
+}
+
+func (srv *server) handleFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := srv.s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondInternalError(w, "error getting feed follows for user", err)
+		return
+	}
+	views := make([]followView, 0, len(follows))
+	for _, follow := range follows {
+		views = append(views, newFollowView(follow))
+	}
+	respondJSON(w, http.StatusOK, views)
+}
+
+func (srv *server) handleFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		Url string `json:"url"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	feed, err := srv.s.db.GetFeedByURL(r.Context(), body.Url)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, http.StatusNotFound, "feed not found")
+		return
+	} else if err != nil {
+		respondInternalError(w, "error getting feed", err)
+		return
+	}
+
+	follow, err := srv.s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		respondWriteError(w, err, "error creating feed follow", "already following this feed", "feed not found")
+		return
+	}
+	respondJSON(w, http.StatusCreated, followView{
+		FeedID:     follow.FeedID,
+		FeedName:   follow.FeedName,
+		FeedUrl:    feed.Url,
+		User:       follow.UserName,
+		FollowedAt: follow.CreatedAt,
+	})
+}
+
+func (srv *server) handleUnfollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, ok := pathUUID(w, r, "feedID")
+	if !ok {
+		return
+	}
+	err := srv.s.db.DeleteByPair(r.Context(), database.DeleteByPairParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	})
+	if err != nil {
+		respondInternalError(w, "error deleting by pair", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePosts lists posts with the same filters as the browse command. Unlike
+// browse it leaves the posts unread; clients mark them read explicitly.
+func (srv *server) handlePosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	limit, ok := queryInt(w, query.Get("limit"), apiDefaultLimit)
+	if !ok {
+		return
+	}
+	offset, ok := queryInt(w, query.Get("offset"), 0)
+	if !ok {
+		return
+	}
+	if limit < 1 || limit > apiMaxLimit || offset < 0 {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d and offset must not be negative", apiMaxLimit))
+		return
+	}
+
+	filter := postFilter{
+		unreadOnly: query.Get("unread") == "true",
+		sortBy:     query.Get("sort"),
+		limit:      int32(limit),
+		offset:     int32(offset),
+	}
+	if feedURL := query.Get("feed"); feedURL != "" {
+		feed, err := srv.s.db.GetFeedByURL(r.Context(), feedURL)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondError(w, http.StatusNotFound, "feed not found")
+			return
+		} else if err != nil {
+			respondInternalError(w, "error getting feed", err)
+			return
+		}
+		filter.feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if folder := normalizeFolder(query.Get("folder")); folder != "" {
+		filter.folder = sql.NullString{String: folder, Valid: true}
+	}
+	if name := query.Get("saved"); name != "" {
+		if filter.feedID.Valid || filter.folder.Valid {
+			respondError(w, http.StatusBadRequest, "saved cannot be combined with feed or folder")
+			return
+		}
+		err := applySavedSearch(r.Context(), srv.s.db, user, name, &filter)
+		if errors.Is(err, errNoSavedSearch) {
+			respondError(w, http.StatusNotFound, "saved search not found")
+			return
+		} else if err != nil {
+			respondInternalError(w, "error getting saved search", err)
+			return
+		}
+	}
+	var err error
+	if filter.since, err = parseTimeFlag(query.Get("since")); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.until, err = parseTimeFlag(query.Get("until")); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if after := query.Get("after"); after != "" {
+		if filter.cursor, err = decodeCursor(after); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if filter.sortBy != "" && filter.sortBy != sortPublished && filter.sortBy != sortFetched {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("sort must be %s or %s", sortPublished, sortFetched))
+		return
+	}
+
+	posts, err := queryPosts(r.Context(), srv.s.db, user.ID, filter)
+	if err != nil {
+		respondInternalError(w, "error getting posts from user", err)
+		return
+	}
+	page := postPageView{Posts: posts}
+	if len(posts) == limit {
+		page.Next = nextCursor(posts, filter.sortBy)
+	}
+	respondJSON(w, http.StatusOK, page)
+}
+
+func (srv *server) handleMarkRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	err := srv.s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: postID,
+		ReadAt: time.Now(),
+	})
+	if err != nil {
+		respondWriteError(w, err, "error marking post as read", "", "post not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *server) handleMarkUnread(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	err := srv.s.db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		respondInternalError(w, "error marking post as unread", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *server) handleStar(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	err := srv.s.db.StarPost(r.Context(), database.StarPostParams{
+		UserID:    user.ID,
+		PostID:    postID,
+		StarredAt: time.Now(),
+	})
+	if err != nil {
+		respondWriteError(w, err, "error starring post", "", "post not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *server) handleUnstar(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	err := srv.s.db.UnstarPost(r.Context(), database.UnstarPostParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		respondInternalError(w, "error unstarring post", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *server) handleStarred(w http.ResponseWriter, r *http.Request, user database.User) {
+	limit, ok := queryInt(w, r.URL.Query().Get("limit"), apiDefaultLimit)
+	if !ok {
+		return
+	}
+	posts, err := srv.s.db.GetStarredPostsForUser(r.Context(), database.GetStarredPostsForUserParams{
+		UserID: user.ID,
+		Limit:  int32(min(max(limit, 1), apiMaxLimit)),
+	})
+	if err != nil {
+		respondInternalError(w, "error getting starred posts", err)
+		return
+	}
+	views := make([]starredPostView, 0, len(posts))
+	for _, post := range posts {
+		views = append(views, newStarredPostView(post))
+	}
+	respondJSON(w, http.StatusOK, views)
+}
+
+func (srv *server) handleTagPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	tag, err := normalizeTag(r.PathValue("tag"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	err = tagPost(r.Context(), srv.s.db, user, postID, []string{tag})
+	if errors.Is(err, errNoPost) {
+		respondError(w, http.StatusNotFound, "post not found")
+		return
+	} else if err != nil {
+		respondInternalError(w, "error tagging post", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *server) handleUntagPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, ok := pathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	tag, err := normalizeTag(r.PathValue("tag"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = srv.s.db.UntagPost(r.Context(), database.UntagPostParams{
+		UserID: user.ID,
+		PostID: postID,
+		Tag:    tag,
+	})
+	if err != nil {
+		respondInternalError(w, "error untagging post", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *server) handleTags(w http.ResponseWriter, r *http.Request, user database.User) {
+	views, err := getTags(r.Context(), srv.s.db, user)
+	if err != nil {
+		respondInternalError(w, "error getting tags", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, views)
+}
+
+func (srv *server) handleTagged(w http.ResponseWriter, r *http.Request, user database.User) {
+	tag, err := normalizeTag(r.PathValue("tag"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, ok := queryInt(w, r.URL.Query().Get("limit"), apiDefaultLimit)
+	if !ok {
+		return
+	}
+	categories := r.URL.Query().Get("categories") == "true"
+	views, err := getTaggedPosts(r.Context(), srv.s.db, user, tag, categories, min(max(limit, 1), apiMaxLimit))
+	if err != nil {
+		respondInternalError(w, "error getting tagged posts", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, views)
+}
+
+func (srv *server) handleSavedSearches(w http.ResponseWriter, r *http.Request, user database.User) {
+	views, err := getSavedSearches(r.Context(), srv.s.db, user)
+	if err != nil {
+		respondInternalError(w, "error getting saved searches", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, views)
+}
+
+func (srv *server) handleSearch(w http.ResponseWriter, r *http.Request, user database.User) {
+	query, err := buildTSQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, ok := queryInt(w, r.URL.Query().Get("limit"), apiDefaultLimit)
+	if !ok {
+		return
+	}
+	results, err := srv.s.db.SearchPostsForUser(r.Context(), database.SearchPostsForUserParams{
+		Query:  query,
+		UserID: user.ID,
+		Limit:  int32(min(max(limit, 1), apiMaxLimit)),
+	})
+	if err != nil {
+		respondInternalError(w, "error searching posts", err)
+		return
+	}
+	views := make([]searchResultView, 0, len(results))
+	for _, result := range results {
+		views = append(views, newSearchResultView(result))
+	}
+	respondJSON(w, http.StatusOK, views)
+}
+
+func respondJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("error encoding response: %v", err)
+	}
+}
+
+func respondError(w http.ResponseWriter, status int, msg string) {
+	respondJSON(w, status, errorView{Error: msg})
+}
+
+// respondInternalError logs the underlying error and hides it from the
+// client.
+func respondInternalError(w http.ResponseWriter, msg string, err error) {
+	log.Printf("%s: %v", msg, err)
+	respondError(w, http.StatusInternalServerError, msg)
+}
+
+// respondWriteError answers a failed insert or update with 409 and conflict
+// when it broke a unique constraint, and 404 and notFound when it referred to
+// a row that doesn't exist. Empty messages and other errors are internal.
+func respondWriteError(w http.ResponseWriter, err error, msg, conflict, notFound string) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == uniqueViolation && conflict != "":
+			respondError(w, http.StatusConflict, conflict)
+			return
+		case pqErr.Code == foreignKeyViolation && notFound != "":
+			respondError(w, http.StatusNotFound, notFound)
+			return
+		}
+	}
+	respondInternalError(w, msg, err)
+}
+
+// decodeBody decodes a JSON request body into v. Requiring the JSON content
+// type means a browser can't send the request from a plain form on another
+// site.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		respondError(w, http.StatusUnsupportedMediaType, "request body must be application/json")
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, apiMaxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must be at most %d bytes", tooLarge.Limit))
+			return false
+		}
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func pathUUID(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s: %v", name, err))
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func queryInt(w http.ResponseWriter, value string, fallback int) (int, bool) {
+	if value == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid number %q", value))
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantOK      bool
+		wantStatus  int
+	}{
+		{name: "json", contentType: "application/json", body: `{"name":"a"}`, wantOK: true},
+		{name: "json with charset", contentType: "application/json; charset=utf-8", body: `{"name":"a"}`, wantOK: true},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: `{"name":"a"}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "plain text", contentType: "text/plain", body: `{"name":"a"}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "no content type", body: `{"name":"a"}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "invalid json", contentType: "application/json", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "too large", contentType: "application/json", body: `{"name":"` + strings.Repeat("a", apiMaxBodyBytes) + `"}`, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			var body struct {
+				Name string `json:"name"`
+			}
+			ok := decodeBody(w, r, &body)
+			if ok != tt.wantOK {
+				t.Fatalf("decodeBody() = %v, want %v (status %d: %s)", ok, tt.wantOK, w.Code, w.Body)
+			}
+			if !ok && w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if ok && body.Name != "a" {
+				t.Errorf("name = %q, want %q", body.Name, "a")
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+const (
+	historyFileName = ".gator_history"
+	historyMaxLines = 500
+)
+
+// handleShell reads commands interactively and runs them against the same
+// config and database connection. On a terminal it offers line editing,
+// history (kept in ~/.gator_history) and tab completion; otherwise it reads
+// one command per line from stdin.
+func (c *commands) handleShell(s *state, _ command) error {
+	if s.inShell {
+		return fmt.Errorf("already in a gator shell")
+	}
+	s.inShell = true
+	defer func() { s.inShell = false }()
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		for {
+			line, err := stdinLines.ReadString('\n')
+			if (err == nil || line != "") && c.runLine(s, strings.TrimRight(line, "\r\n")) {
+				return nil
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("error reading input: %v", err)
+			}
+		}
+	}
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	if history, err := loadHistory(); err == nil {
+		t.History = history
+	}
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return c.autoComplete(s, line, pos)
+	}
+
+	fmt.Println("gator shell: type \"help\" for commands, \"exit\" or Ctrl-D to quit")
+	for {
+		if width, height, err := term.GetSize(fd); err == nil {
+			t.SetSize(width, height)
+		}
+		t.SetPrompt(shellPrompt(s))
+
+		// Raw mode is only needed while editing the line; commands print
+		// ordinary output with the terminal restored.
+		oldState, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("error setting up terminal: %v", err)
+		}
+		line, err := t.ReadLine()
+		term.Restore(fd, oldState)
+		if errors.Is(err, io.EOF) {
+			fmt.Println()
+			return nil
+		} else if err != nil && !errors.Is(err, term.ErrPasteIndicator) {
+			return fmt.Errorf("error reading input: %v", err)
+		}
+
+		if c.runLine(s, line) {
+			return nil
+		}
+	}
+}
+
+func shellPrompt(s *state) string {
+	if user := s.cfg.GetUser(); user != "" {
+		return fmt.Sprintf("gator (%s)> ", user)
+	}
+	return "gator> "
+}
+
+// runLine runs a single line typed in the shell. Global flags such as
+// --output may precede the command and apply to that line only. It returns
+// true when the user asked to leave the shell.
+func (c *commands) runLine(s *state, line string) bool {
+	words, err := splitArgs(line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return false
+	}
+	if len(words) == 0 {
+		return false
+	}
+	if words[0] == "exit" || words[0] == "quit" {
+		return true
+	}
+
+	var opts globalOptions
+	global := globalFlagSet(&opts)
+	global.SetOutput(io.Discard)
+	if err = global.Parse(words); err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return false
+	}
+	if global.NArg() == 0 {
+		c.printHelp(os.Stdout)
+		return false
+	}
+
+	lineState := s
+	if global.NFlag() > 0 {
+		out, err := newOutput(opts, s.cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return false
+		}
+		copied := *s
+		copied.out = out
+		copied.opts = opts
+		lineState = &copied
+	}
+
+	cmd := command{
+		name: global.Arg(0),
+		args: global.Args()[1:],
+	}
+	if err = c.run(lineState, cmd); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+	return false
+}
+
+// autoComplete completes the word under the cursor to the longest prefix
+// shared by all candidates.
+func (c *commands) autoComplete(s *state, line string, pos int) (string, int, bool) {
+	before := line[:pos]
+	words, err := splitArgs(before)
+	if err != nil {
+		return "", 0, false
+	}
+	if before == "" || unicode.IsSpace(rune(before[len(before)-1])) {
+		words = append(words, "")
+	}
+
+	candidates := c.complete(s, words)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+	current := words[len(words)-1]
+	completion := candidates[0].value
+	for _, cand := range candidates[1:] {
+		completion = commonPrefix(completion, cand.value)
+	}
+	if len(candidates) == 1 {
+		completion += " "
+	}
+	if len(completion) <= len(current) {
+		return "", 0, false
+	}
+
+	suffix := completion[len(current):]
+	return before + suffix + line[pos:], pos + len(suffix), true
+}
+
+func commonPrefix(a, b string) string {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return a[:i]
+		}
+	}
+	return a[:n]
+}
+
+// splitArgs splits a line into words the way a shell would, honouring single
+// quotes, double quotes and backslash escapes.
+func splitArgs(line string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// fileHistory keeps shell history in memory and appends each new entry to
+// the history file so it survives across sessions.
+type fileHistory struct {
+	entries []string
+	path    string
+}
+
+func loadHistory() (*fileHistory, error) {
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	h := &fileHistory{path: filepath.Join(homedir, historyFileName)}
+	data, err := os.ReadFile(h.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if len(h.entries) > historyMaxLines {
+		h.entries = h.entries[len(h.entries)-historyMaxLines:]
+	}
+	return h, nil
+}
+
+func (h *fileHistory) Add(entry string) {
+	if entry == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > historyMaxLines {
+		h.entries = h.entries[1:]
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, entry)
+}
+
+func (h *fileHistory) Len() int {
+	return len(h.entries)
+}
+
+func (h *fileHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
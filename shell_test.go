@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{line: "", want: nil},
+		{line: "   ", want: nil},
+		{line: "browse 5", want: []string{"browse", "5"}},
+		{line: "  follow\t https://example.com  ", want: []string{"follow", "https://example.com"}},
+		{line: `addfeed "Go Blog" https://go.dev/blog/feed.atom`, want: []string{"addfeed", "Go Blog", "https://go.dev/blog/feed.atom"}},
+		{line: `search 'exact "phrase"'`, want: []string{"search", `exact "phrase"`}},
+		{line: `search "it's"`, want: []string{"search", "it's"}},
+		{line: `a\ b c`, want: []string{"a b", "c"}},
+		{line: `"a\"b"`, want: []string{`a"b`}},
+		{line: `'a\b'`, want: []string{`a\b`}},
+		{line: `x""y ''`, want: []string{"xy", ""}},
+		{line: `"unterminated`, wantErr: true},
+		{line: `it's`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.line)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitArgs(%q) = %q, want an error", tt.line, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitArgs(%q) returned error: %v", tt.line, err)
+		} else if !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -20,14 +20,51 @@ INNER JOIN feeds ON feeds.id = inserted_feed_follow.feed_id;
 -- name: GetFeedFollowsForUser :many
 SELECT
     feed_follows.*,
-    feeds.name AS feed_name,
-    users.name AS user_name
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    feeds.url AS feed_url,
+    feeds.short_id AS feed_short_id,
+    users.name AS user_name,
+    (
+        SELECT COUNT(*) FROM posts
+        WHERE posts.feed_id = feed_follows.feed_id
+        AND NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id
+            AND post_reads.user_id = feed_follows.user_id
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM post_hides
+            WHERE post_hides.post_id = posts.id
+            AND post_hides.user_id = feed_follows.user_id
+        )
+    ) AS unread_count
 FROM feed_follows
 INNER JOIN users ON users.id = feed_follows.user_id
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1;
+WHERE feed_follows.user_id = $1
+ORDER BY string_to_array(feed_follows.folder, '/'), feed_follows.position, feed_name;
 
 -- name: DeleteByPair :exec
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2
 RETURNING *;
+
+-- name: UpdateFeedFollow :one
+UPDATE feed_follows
+SET title = $3, folder = $4, position = $5, updated_at = $6
+WHERE user_id = $1 AND feed_id = $2
+RETURNING *;
+
+-- name: IsFollowingFeed :one
+SELECT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE user_id = $1 AND feed_id = $2
+);
+
+-- name: IsFollowingPost :one
+-- Reports whether the user follows the feed the post is from.
+SELECT EXISTS (
+    SELECT 1 FROM posts
+    JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+    WHERE feed_follows.user_id = $1 AND posts.id = $2
+);
@@ -1,5 +1,5 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, added_by)
 VALUES (
     $1,
     $2,
@@ -24,3 +24,68 @@ WHERE id = $1;
 -- name: GetNextFeedToFetch :one
 SELECT * FROM feeds
 ORDER BY last_fetched_at ASC NULLS FIRST;
+
+-- name: SetFeedOwner :exec
+UPDATE feeds
+SET added_by = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: TransferFeeds :execrows
+UPDATE feeds
+SET added_by = $2, updated_at = $3
+WHERE added_by = $1;
+
+-- name: GetOrphanedFeeds :many
+SELECT
+    feeds.*,
+    (SELECT COUNT(*) FROM posts WHERE posts.feed_id = feeds.id) AS post_count
+FROM feeds
+WHERE NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM user_post_stars
+    INNER JOIN posts ON posts.id = user_post_stars.post_id
+    WHERE posts.feed_id = feeds.id
+)
+ORDER BY feeds.name;
+
+-- name: DeleteOrphanedFeeds :execrows
+DELETE FROM feeds
+WHERE feeds.id = ANY(sqlc.arg(ids)::uuid[])
+AND NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM user_post_stars
+    INNER JOIN posts ON posts.id = user_post_stars.post_id
+    WHERE posts.feed_id = feeds.id
+);
+
+-- name: UpdateFeed :one
+UPDATE feeds
+SET name = $2, url = $3, updated_at = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: GetFeedStats :one
+-- Counts what deleting a feed would remove, and how much of it belongs to
+-- users other than user_id.
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = sqlc.arg(feed_id)) AS followers,
+    (
+        SELECT COUNT(*) FROM feed_follows
+        WHERE feed_follows.feed_id = sqlc.arg(feed_id) AND feed_follows.user_id <> sqlc.arg(user_id)
+    ) AS other_followers,
+    (SELECT COUNT(*) FROM posts WHERE posts.feed_id = sqlc.arg(feed_id)) AS posts,
+    (
+        SELECT COUNT(*) FROM user_post_stars
+        JOIN posts ON posts.id = user_post_stars.post_id
+        WHERE posts.feed_id = sqlc.arg(feed_id) AND user_post_stars.user_id <> sqlc.arg(user_id)
+    ) AS other_stars;
+
+-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1;
@@ -0,0 +1,14 @@
+-- name: SetFeverAPIKey :exec
+INSERT INTO fever_credentials (user_id, api_key, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id) DO UPDATE
+SET api_key = EXCLUDED.api_key, created_at = EXCLUDED.created_at;
+
+-- name: GetUserByFeverAPIKey :one
+SELECT users.* FROM users
+JOIN fever_credentials ON fever_credentials.user_id = users.id
+WHERE fever_credentials.api_key = $1;
@@ -0,0 +1,51 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, user_id, name, field, match_type, pattern, feed_id, folder, action, tag, created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12
+)
+RETURNING *;
+
+-- name: GetFilterRulesForUser :many
+SELECT filter_rules.*, feeds.url AS feed_url FROM filter_rules
+LEFT JOIN feeds ON feeds.id = filter_rules.feed_id
+WHERE filter_rules.user_id = $1
+ORDER BY filter_rules.name;
+
+-- name: GetFilterRulesForFeed :many
+SELECT filter_rules.* FROM filter_rules
+JOIN feed_follows ON feed_follows.user_id = filter_rules.user_id
+AND feed_follows.feed_id = sqlc.arg(feed_id)
+WHERE (filter_rules.feed_id IS NULL OR filter_rules.feed_id = sqlc.arg(feed_id))
+AND (
+    filter_rules.folder IS NULL
+    OR feed_follows.folder = filter_rules.folder
+    OR starts_with(feed_follows.folder, filter_rules.folder || '/')
+)
+ORDER BY filter_rules.created_at;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetPostsForFilterRule :many
+SELECT posts.id, posts.title, posts.description, posts.author, posts.content_text FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND (
+    sqlc.narg(folder)::text IS NULL
+    OR feed_follows.folder = sqlc.narg(folder)
+    OR starts_with(feed_follows.folder, sqlc.narg(folder) || '/')
+)
+ORDER BY posts.published_at DESC;
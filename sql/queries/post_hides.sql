@@ -0,0 +1,24 @@
+-- name: HidePost :exec
+INSERT INTO post_hides (user_id, post_id, rule_id, hidden_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id, rule_id) DO NOTHING;
+
+-- name: GetPostsHiddenOnlyByFilterRule :many
+-- Posts that the user's rule called name hides and no other rule does, so
+-- that deleting the rule shows them again.
+SELECT posts.id, posts.title, posts.url FROM posts
+JOIN post_hides ON post_hides.post_id = posts.id
+JOIN filter_rules ON filter_rules.id = post_hides.rule_id
+WHERE filter_rules.user_id = $1 AND filter_rules.name = $2
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides AS other_hides
+    WHERE other_hides.post_id = posts.id
+    AND other_hides.user_id = post_hides.user_id
+    AND other_hides.rule_id <> post_hides.rule_id
+)
+ORDER BY posts.published_at DESC;
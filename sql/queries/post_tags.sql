@@ -0,0 +1,56 @@
+-- name: TagPost :exec
+INSERT INTO post_tags (user_id, post_id, tag, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id, tag) DO NOTHING;
+
+-- name: UntagPost :execrows
+DELETE FROM post_tags
+WHERE user_id = $1 AND post_id = $2 AND tag = $3;
+
+-- name: GetTagsForUser :many
+SELECT tag, COUNT(*) AS post_count FROM post_tags
+WHERE user_id = $1
+GROUP BY tag
+ORDER BY tag;
+
+-- name: GetPostsByTag :many
+SELECT
+    posts.*,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    ARRAY(
+        SELECT post_tags.tag FROM post_tags
+        WHERE post_tags.post_id = posts.id
+        AND post_tags.user_id = sqlc.arg(user_id)
+        ORDER BY post_tags.tag
+    )::text[] AS tags,
+    ARRAY(
+        SELECT post_categories.category FROM post_categories
+        WHERE post_categories.post_id = posts.id
+        ORDER BY post_categories.category
+    )::text[] AS categories
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+AND feed_follows.user_id = sqlc.arg(user_id)
+WHERE EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id
+    AND post_tags.user_id = sqlc.arg(user_id)
+    AND post_tags.tag = sqlc.arg(tag)
+)
+OR (
+    sqlc.arg(include_categories)::boolean
+    AND feed_follows.id IS NOT NULL
+    AND EXISTS (
+        SELECT 1 FROM post_categories
+        WHERE post_categories.post_id = posts.id
+        AND lower(post_categories.category) = sqlc.arg(tag)
+    )
+)
+ORDER BY posts.published_at DESC
+LIMIT sqlc.arg('limit');
@@ -1,5 +1,5 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content_text, author)
 VALUES (
     $1,
     $2,
@@ -8,17 +8,229 @@ VALUES (
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10
 )
 RETURNING *;
 
 -- name: GetPostsFromUser :many
-SELECT posts.* FROM posts
+SELECT posts.*, COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name, feeds.url AS feed_url FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
 WHERE feed_follows.user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
 ORDER BY posts.published_at DESC
 LIMIT $2;
 
 -- name: GetPostByURL :one
 SELECT * FROM posts
 WHERE url = $1;
+
+-- name: GetPostForUser :one
+SELECT
+    posts.*,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = sqlc.arg(user_id)
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+        AND user_post_stars.user_id = sqlc.arg(user_id)
+    ) AS is_starred
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+AND feed_follows.user_id = sqlc.arg(user_id)
+WHERE posts.id = sqlc.arg(id);
+
+-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    posts.feed_id,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    ts_rank(posts.search_vector, to_tsquery('english', sqlc.arg(query))) AS rank,
+    ts_headline(
+        'english',
+        posts.title,
+        to_tsquery('english', sqlc.arg(query)),
+        'StartSel=[[, StopSel=]], HighlightAll=true'
+    ) AS title_headline,
+    ts_headline(
+        'english',
+        COALESCE(NULLIF(posts.content_text, ''), posts.description),
+        to_tsquery('english', sqlc.arg(query)),
+        'StartSel=[[, StopSel=]], MaxFragments=2, MaxWords=25, MinWords=10'
+    ) AS description_headline
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+AND posts.search_vector @@ to_tsquery('english', sqlc.arg(query))
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: BrowsePostsByPublished :many
+SELECT
+    posts.*,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+        AND user_post_stars.user_id = feed_follows.user_id
+    ) AS is_starred
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until))
+AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id
+    AND post_reads.user_id = feed_follows.user_id
+))
+AND (
+    sqlc.narg(cursor_time)::timestamp IS NULL
+    OR (posts.published_at, posts.id) < (sqlc.narg(cursor_time), sqlc.narg(cursor_id)::uuid)
+)
+AND (
+    sqlc.narg(folder)::text IS NULL
+    OR feed_follows.folder = sqlc.narg(folder)
+    OR starts_with(feed_follows.folder, sqlc.narg(folder) || '/')
+)
+AND (
+    sqlc.narg(ts_query)::text IS NULL
+    OR posts.search_vector @@ to_tsquery('english', sqlc.narg(ts_query))
+)
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: BrowsePostsByFetched :many
+SELECT
+    posts.*,
+    COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+        AND user_post_stars.user_id = feed_follows.user_id
+    ) AS is_starred
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.created_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.created_at < sqlc.narg(until))
+AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id
+    AND post_reads.user_id = feed_follows.user_id
+))
+AND (
+    sqlc.narg(cursor_time)::timestamp IS NULL
+    OR (posts.created_at, posts.id) < (sqlc.narg(cursor_time), sqlc.narg(cursor_id)::uuid)
+)
+AND (
+    sqlc.narg(folder)::text IS NULL
+    OR feed_follows.folder = sqlc.narg(folder)
+    OR starts_with(feed_follows.folder, sqlc.narg(folder) || '/')
+)
+AND (
+    sqlc.narg(ts_query)::text IS NULL
+    OR posts.search_vector @@ to_tsquery('english', sqlc.narg(ts_query))
+)
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetPostByShortID :one
+SELECT * FROM posts
+WHERE short_id = $1;
+
+-- name: GetPostsForUserByShortID :many
+SELECT
+    posts.*,
+    feeds.short_id AS feed_short_id,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+        AND user_post_stars.user_id = feed_follows.user_id
+    ) AS is_starred
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+AND (sqlc.narg(since_id)::bigint IS NULL OR posts.short_id > sqlc.narg(since_id))
+AND (sqlc.narg(max_id)::bigint IS NULL OR posts.short_id < sqlc.narg(max_id))
+AND (sqlc.narg(ids)::bigint[] IS NULL OR posts.short_id = ANY(sqlc.narg(ids)::bigint[]))
+ORDER BY CASE WHEN sqlc.narg(max_id)::bigint IS NULL THEN posts.short_id ELSE -posts.short_id END
+LIMIT sqlc.arg('limit');
+
+-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1;
+
+-- name: GetUnreadPostShortIDsForUser :many
+SELECT posts.short_id FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id
+    AND post_reads.user_id = feed_follows.user_id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM post_hides
+    WHERE post_hides.post_id = posts.id
+    AND post_hides.user_id = feed_follows.user_id
+)
+ORDER BY posts.short_id;
@@ -0,0 +1,18 @@
+-- name: SetPublishToken :exec
+INSERT INTO publish_tokens (user_id, token_hash, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at;
+
+-- name: DeletePublishToken :execrows
+DELETE FROM publish_tokens
+WHERE user_id = $1;
+
+-- name: GetUserByPublishToken :one
+SELECT users.* FROM users
+JOIN publish_tokens ON publish_tokens.user_id = users.id
+WHERE users.name = $1 AND publish_tokens.token_hash = $2;
@@ -0,0 +1,99 @@
+-- name: GetRetentionSettings :one
+SELECT * FROM retention_settings;
+
+-- name: SetRetentionSettings :exec
+UPDATE retention_settings
+SET keep_days = $1,
+    keep_posts = $2,
+    updated_at = $3;
+
+-- name: SetFeedRetention :exec
+INSERT INTO feed_retention (feed_id, keep_days, keep_posts, updated_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (feed_id) DO UPDATE
+SET keep_days = excluded.keep_days,
+    keep_posts = excluded.keep_posts,
+    updated_at = excluded.updated_at;
+
+-- name: DeleteFeedRetention :execrows
+DELETE FROM feed_retention
+WHERE feed_id = $1;
+
+-- name: GetFeedRetentions :many
+SELECT feed_retention.*, feeds.name AS feed_name, feeds.url AS feed_url FROM feed_retention
+JOIN feeds ON feeds.id = feed_retention.feed_id
+ORDER BY feeds.name;
+
+-- name: GetPrunablePosts :many
+-- Posts past their feed's age limit or beyond its newest keep_posts posts.
+-- Starred posts are never pruned, but still count towards keep_posts.
+WITH ranked AS (
+    SELECT
+        posts.id,
+        posts.feed_id,
+        posts.published_at,
+        ROW_NUMBER() OVER (
+            PARTITION BY posts.feed_id
+            ORDER BY posts.published_at DESC, posts.id DESC
+        ) AS position
+    FROM posts
+)
+SELECT ranked.id, ranked.feed_id, feeds.name AS feed_name, feeds.url AS feed_url
+FROM ranked
+JOIN feeds ON feeds.id = ranked.feed_id
+CROSS JOIN retention_settings
+LEFT JOIN feed_retention ON feed_retention.feed_id = ranked.feed_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM user_post_stars
+    WHERE user_post_stars.post_id = ranked.id
+)
+AND (
+    (
+        COALESCE(feed_retention.keep_days, retention_settings.keep_days) > 0
+        AND ranked.published_at < sqlc.arg(now)::timestamp
+            - make_interval(days => COALESCE(feed_retention.keep_days, retention_settings.keep_days))
+    )
+    OR (
+        COALESCE(feed_retention.keep_posts, retention_settings.keep_posts) > 0
+        AND ranked.position > COALESCE(feed_retention.keep_posts, retention_settings.keep_posts)
+    )
+)
+ORDER BY feeds.name, ranked.feed_id;
+
+-- name: PrunePosts :execrows
+WITH pruned AS (
+    INSERT INTO pruned_posts (url, feed_id, pruned_at, seen_at)
+    SELECT posts.url, posts.feed_id, sqlc.arg(pruned_at)::timestamp, NOW() FROM posts
+    WHERE posts.id = ANY(sqlc.arg(ids)::uuid[])
+    AND NOT EXISTS (
+        SELECT 1 FROM user_post_stars
+        WHERE user_post_stars.post_id = posts.id
+    )
+    ON CONFLICT (url) DO NOTHING
+)
+DELETE FROM posts
+WHERE posts.id = ANY(sqlc.arg(ids)::uuid[])
+AND NOT EXISTS (
+    SELECT 1 FROM user_post_stars
+    WHERE user_post_stars.post_id = posts.id
+);
+
+-- name: MarkPrunedPostSeen :execrows
+-- Records that a pruned post is still in its feed, so that it isn't expired.
+UPDATE pruned_posts
+SET seen_at = NOW()
+WHERE url = $1;
+
+-- name: ExpirePrunedPosts :execrows
+-- Forgets pruned posts that were missing from their feed's latest fetch.
+-- MarkFeedFetched runs before the items are checked, so any URL still in
+-- the feed was seen after it.
+DELETE FROM pruned_posts
+USING feeds
+WHERE feeds.id = pruned_posts.feed_id
+AND feeds.last_fetched_at > pruned_posts.seen_at;
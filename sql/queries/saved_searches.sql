@@ -0,0 +1,56 @@
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (id, user_id, name, query, ts_query, feed_id, folder, created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+RETURNING *;
+
+-- name: GetSavedSearch :one
+SELECT * FROM saved_searches
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetSavedSearchesForUser :many
+SELECT
+    saved_searches.*,
+    feeds.url AS feed_url,
+    (
+        SELECT COUNT(*) FROM posts
+        JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+        WHERE feed_follows.user_id = saved_searches.user_id
+        AND (saved_searches.feed_id IS NULL OR posts.feed_id = saved_searches.feed_id)
+        AND (
+            saved_searches.folder IS NULL
+            OR feed_follows.folder = saved_searches.folder
+            OR starts_with(feed_follows.folder, saved_searches.folder || '/')
+        )
+        AND (
+            saved_searches.ts_query IS NULL
+            OR posts.search_vector @@ to_tsquery('english', saved_searches.ts_query)
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id
+            AND post_reads.user_id = feed_follows.user_id
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM post_hides
+            WHERE post_hides.post_id = posts.id
+            AND post_hides.user_id = feed_follows.user_id
+        )
+    ) AS unread_count
+FROM saved_searches
+LEFT JOIN feeds ON feeds.id = saved_searches.feed_id
+WHERE saved_searches.user_id = $1
+ORDER BY saved_searches.name;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches
+WHERE user_id = $1 AND name = $2;
@@ -0,0 +1,28 @@
+-- name: StarPost :exec
+INSERT INTO user_post_stars (user_id, post_id, starred_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnstarPost :exec
+DELETE FROM user_post_stars
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetStarredPostsForUser :many
+SELECT posts.*, COALESCE(NULLIF(feed_follows.title, ''), feeds.name) AS feed_name, user_post_stars.starred_at FROM posts
+JOIN user_post_stars ON user_post_stars.post_id = posts.id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+AND feed_follows.user_id = user_post_stars.user_id
+WHERE user_post_stars.user_id = $1
+ORDER BY user_post_stars.starred_at DESC
+LIMIT $2;
+
+-- name: GetStarredPostShortIDsForUser :many
+SELECT posts.short_id FROM posts
+JOIN user_post_stars ON user_post_stars.post_id = posts.id
+WHERE user_post_stars.user_id = $1
+ORDER BY posts.short_id;
@@ -1,10 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, hashed_password, role)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 )
 RETURNING *;
 
@@ -13,11 +15,50 @@ SELECT * FROM users
 WHERE name = $1;
 
 -- name: Reset :exec
+-- Feeds outlive their owners, so they are deleted too; posts and follows
+-- go with them.
+WITH deleted_feeds AS (
+    DELETE FROM feeds
+)
 DELETE FROM users;
 
 -- name: GetUsers :many
 SELECT * FROM users;
 
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: PromoteFirstAdmin :execrows
+-- Makes a user with a password an admin if there is no admin yet, checking
+-- and updating in one statement.
+UPDATE users
+SET role = 'admin', updated_at = $2
+WHERE id = $1
+AND hashed_password <> ''
+AND NOT EXISTS (
+    SELECT 1 FROM users AS admins
+    WHERE admins.role = 'admin'
+);
+
 -- name: GetUserByID :one
 SELECT * FROM users
 WHERE id = $1;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET hashed_password = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: SetUserRole :exec
+UPDATE users
+SET role = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: RenameUser :exec
+UPDATE users
+SET name = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
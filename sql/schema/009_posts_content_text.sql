@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN content_text TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN content_text;
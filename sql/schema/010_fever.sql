@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN short_id BIGSERIAL UNIQUE;
+
+ALTER TABLE posts ADD COLUMN short_id BIGSERIAL UNIQUE;
+
+CREATE TABLE fever_credentials (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    api_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE fever_credentials;
+
+ALTER TABLE posts
+DROP COLUMN short_id;
+
+ALTER TABLE feeds
+DROP COLUMN short_id;
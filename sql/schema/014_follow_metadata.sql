@@ -0,0 +1,14 @@
+-- +goose Up
+-- Per-user subscription settings. An empty title means the feed's own name;
+-- folders nest with "/" (e.g. "Tech/Go"), the way OPML outlines do, and an
+-- empty folder is the top level.
+ALTER TABLE feed_follows
+ADD COLUMN title TEXT NOT NULL DEFAULT '',
+ADD COLUMN folder TEXT NOT NULL DEFAULT '',
+ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feed_follows
+DROP COLUMN position,
+DROP COLUMN folder,
+DROP COLUMN title;
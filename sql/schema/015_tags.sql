@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE post_tags (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id, tag)
+);
+
+CREATE INDEX post_tags_user_tag_idx ON post_tags (user_id, tag);
+
+-- Categories are the <category> elements of feed items, shared by everyone.
+CREATE TABLE post_categories (
+    post_id UUID NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    PRIMARY KEY (post_id, category)
+);
+
+CREATE INDEX post_categories_category_idx ON post_categories (lower(category));
+
+-- +goose Down
+DROP TABLE post_categories;
+
+DROP TABLE post_tags;
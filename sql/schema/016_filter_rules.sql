@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN author TEXT NOT NULL DEFAULT '';
+
+-- Filter rules match new posts by keyword or regular expression, optionally
+-- only in one feed or folder, and hide, mark read, star or tag them for
+-- their owner. A NULL feed_id or folder means any feed or folder.
+CREATE TABLE filter_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    field TEXT NOT NULL CHECK (field IN ('any', 'title', 'description', 'author')),
+    match_type TEXT NOT NULL CHECK (match_type IN ('keyword', 'regex')),
+    pattern TEXT NOT NULL,
+    feed_id UUID REFERENCES feeds (id) ON DELETE CASCADE,
+    folder TEXT,
+    action TEXT NOT NULL CHECK (action IN ('hide', 'read', 'star', 'tag')),
+    tag TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+-- Posts are hidden by rules, so deleting a rule shows its posts again.
+CREATE TABLE post_hides (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    rule_id UUID NOT NULL REFERENCES filter_rules (id) ON DELETE CASCADE,
+    hidden_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id, rule_id)
+);
+
+-- +goose Down
+DROP TABLE post_hides;
+
+DROP TABLE filter_rules;
+
+ALTER TABLE posts DROP COLUMN author;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- A saved search is a named set of browse filters that gator shows like a
+-- feed. query is the search as the user typed it and ts_query its
+-- to_tsquery form; a NULL ts_query, feed_id or folder doesn't filter.
+CREATE TABLE saved_searches (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    ts_query TEXT,
+    feed_id UUID REFERENCES feeds (id) ON DELETE CASCADE,
+    folder TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+-- +goose Down
+DROP TABLE saved_searches;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Per-feed overrides of the default retention policy. A NULL column falls
+-- back to the default and 0 keeps posts forever.
+CREATE TABLE feed_retention (
+    feed_id UUID PRIMARY KEY REFERENCES feeds (id) ON DELETE CASCADE,
+    keep_days INTEGER CHECK (keep_days >= 0),
+    keep_posts INTEGER CHECK (keep_posts >= 0),
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- URLs of pruned posts, so that posts still in a feed aren't fetched again
+-- as new posts.
+CREATE TABLE pruned_posts (
+    url TEXT PRIMARY KEY,
+    feed_id UUID NOT NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    pruned_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE pruned_posts;
+
+DROP TABLE feed_retention;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- 012_roles made the oldest user an admin even though they had no password
+-- yet. Demote passwordless admins; the operator can run "gator promote".
+UPDATE users SET role = 'user'
+WHERE role = 'admin' AND hashed_password = '';
+
+-- +goose Down
+SELECT 1;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- The default retention policy, shared by everyone pruning this database.
+-- The table has exactly one row.
+CREATE TABLE retention_settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    keep_days INTEGER NOT NULL DEFAULT 0 CHECK (keep_days >= 0),
+    keep_posts INTEGER NOT NULL DEFAULT 0 CHECK (keep_posts >= 0),
+    updated_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO retention_settings (updated_at) VALUES (NOW());
+
+-- +goose Down
+DROP TABLE retention_settings;
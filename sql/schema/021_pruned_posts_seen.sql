@@ -0,0 +1,11 @@
+-- +goose Up
+-- When a pruned post's URL was last seen in its feed. Once the feed has been
+-- fetched without it, the URL can't come back as a new post and is forgotten.
+ALTER TABLE pruned_posts ADD COLUMN seen_at TIMESTAMP;
+
+UPDATE pruned_posts SET seen_at = pruned_at;
+
+ALTER TABLE pruned_posts ALTER COLUMN seen_at SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE pruned_posts DROP COLUMN seen_at;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Secret tokens for the feeds "gator serve" publishes at
+-- /users/{name}/feed.rss. A user's feed is only served to requests with
+-- their token, and not at all without one.
+CREATE TABLE publish_tokens (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE publish_tokens;
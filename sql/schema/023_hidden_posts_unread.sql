@@ -0,0 +1,13 @@
+-- +goose Up
+-- Hide rules used to mark the posts they hid as read, so the posts stayed
+-- read after the rule was deleted. Hidden posts are now left out of unread
+-- counts instead; forget the reads the rules made, which were recorded in
+-- the same moment as the hide.
+DELETE FROM post_reads
+USING post_hides
+WHERE post_reads.user_id = post_hides.user_id
+AND post_reads.post_id = post_hides.post_id
+AND post_reads.read_at BETWEEN post_hides.hidden_at AND post_hides.hidden_at + INTERVAL '1 second';
+
+-- +goose Down
+SELECT 1;
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Tags are labels users put on posts, and are private to each user. Feeds
+// can also give their items categories, which are shared by everyone and can
+// be listed alongside a tag with "gator tagged --categories".
+
+// normalizeTag trims and lowercases tag so that "Go" and "go " are the same
+// tag, and so that tags match categories case-insensitively.
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", fmt.Errorf("tag cannot be empty")
+	}
+	return tag, nil
+}
+
+func normalizeTags(args []string) ([]string, error) {
+	tags := make([]string, 0, len(args))
+	for _, arg := range args {
+		tag, err := normalizeTag(arg)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+var errNoPost = errors.New("post not found")
+
+// tagPost adds tags to a post. It returns errNoPost if there is no such post.
+func tagPost(ctx context.Context, db *database.Queries, user database.User, postID uuid.UUID, tags []string) error {
+	for _, tag := range tags {
+		err := db.TagPost(ctx, database.TagPostParams{
+			UserID:    user.ID,
+			PostID:    postID,
+			Tag:       tag,
+			CreatedAt: time.Now(),
+		})
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return errNoPost
+		} else if err != nil {
+			return fmt.Errorf("error tagging post: %v", err)
+		}
+	}
+	return nil
+}
+
+func handleTag(s *state, cmd command, user database.User) error {
+	ids, err := parsePostIDs(cmd.args[:1])
+	if err != nil {
+		return err
+	}
+	tags, err := normalizeTags(cmd.args[1:])
+	if err != nil {
+		return err
+	}
+	err = tagPost(context.Background(), s.db, user, ids[0], tags)
+	if errors.Is(err, errNoPost) {
+		return fmt.Errorf("no post with ID %s", ids[0])
+	} else if err != nil {
+		return err
+	}
+	return s.out.print(countView{Action: "tag", Count: int64(len(tags))}, func() {
+		fmt.Printf("Tagged post %s with %s\n", ids[0], strings.Join(tags, ", "))
+	})
+}
+
+func handleUntag(s *state, cmd command, user database.User) error {
+	ids, err := parsePostIDs(cmd.args[:1])
+	if err != nil {
+		return err
+	}
+	tags, err := normalizeTags(cmd.args[1:])
+	if err != nil {
+		return err
+	}
+	var removed int64
+	for _, tag := range tags {
+		n, err := s.db.UntagPost(context.Background(), database.UntagPostParams{
+			UserID: user.ID,
+			PostID: ids[0],
+			Tag:    tag,
+		})
+		if err != nil {
+			return fmt.Errorf("error untagging post: %v", err)
+		}
+		removed += n
+	}
+	return s.out.print(countView{Action: "untag", Count: removed}, func() {
+		fmt.Printf("Removed %d tags from post %s\n", removed, ids[0])
+	})
+}
+
+func getTags(ctx context.Context, db *database.Queries, user database.User) ([]tagView, error) {
+	rows, err := db.GetTagsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tags: %v", err)
+	}
+	views := make([]tagView, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, tagView{Tag: row.Tag, Posts: row.PostCount})
+	}
+	return views, nil
+}
+
+func handleTags(s *state, _ command, user database.User) error {
+	views, err := getTags(context.Background(), s.db, user)
+	if err != nil {
+		return err
+	}
+	return s.out.print(views, func() {
+		if len(views) == 0 {
+			fmt.Println("No tags")
+		}
+		for _, tag := range views {
+			fmt.Printf("* %s (%d posts)\n", tag.Tag, tag.Posts)
+		}
+	})
+}
+
+func getTaggedPosts(ctx context.Context, db *database.Queries, user database.User, tag string, categories bool, limit int) ([]taggedPostView, error) {
+	posts, err := db.GetPostsByTag(ctx, database.GetPostsByTagParams{
+		UserID:            user.ID,
+		Tag:               tag,
+		IncludeCategories: categories,
+		Limit:             int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting tagged posts: %v", err)
+	}
+	views := make([]taggedPostView, 0, len(posts))
+	for _, post := range posts {
+		views = append(views, newTaggedPostView(post))
+	}
+	return views, nil
+}
+
+func handleTagged(s *state, cmd command, user database.User) error {
+	tag, err := normalizeTag(cmd.args[0])
+	if err != nil {
+		return err
+	}
+	views, err := getTaggedPosts(context.Background(), s.db, user, tag, cmd.flagBool("categories"), cmd.flagInt("limit"))
+	if err != nil {
+		return err
+	}
+	return s.out.print(views, func() {
+		if len(views) == 0 {
+			fmt.Printf("No posts tagged %s\n", tag)
+		}
+		for i, post := range views {
+			fmt.Printf("\n--- Post #%d ---\n", i+1)
+			fmt.Printf("ID: %s\n", post.ID)
+			fmt.Printf("Title: %s\n", post.Title)
+			fmt.Printf("Feed: %s\n", post.FeedName)
+			fmt.Printf("URL: %s\n", post.Url)
+			fmt.Printf("Published: %s\n", post.PublishedAt.Format(time.RFC1123))
+			if len(post.Tags) > 0 {
+				fmt.Printf("Tags: %s\n", strings.Join(post.Tags, ", "))
+			}
+			if len(post.Categories) > 0 {
+				fmt.Printf("Categories: %s\n", strings.Join(post.Categories, ", "))
+			}
+		}
+	})
+}
+
+func completeTags(s *state) ([]candidate, error) {
+	user, err := currentUser(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.db.GetTagsForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidate, 0, len(tags))
+	for _, tag := range tags {
+		candidates = append(candidates, candidate{value: tag.Tag})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,553 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+const (
+	ansiReset      = "\033[0m"
+	ansiBold       = "\033[1m"
+	ansiDim        = "\033[2m"
+	ansiReverse    = "\033[7m"
+	ansiClear      = "\033[2J"
+	ansiHome       = "\033[H"
+	ansiAltScreen  = "\033[?1049h"
+	ansiMainScreen = "\033[?1049l"
+	ansiHideCursor = "\033[?25l"
+	ansiShowCursor = "\033[?25h"
+
+	tuiPostLimit = 200
+)
+
+type tuiPane int
+
+const (
+	paneFeeds tuiPane = iota
+	panePosts
+	paneReader
+)
+
+type tuiFeed struct {
+	id     uuid.NullUUID
+	name   string
+	url    string
+	unread int64
+}
+
+// tui is the full-screen reader: a feed list on the left, the posts of the
+// selected feed on the top right and the selected post below them.
+type tui struct {
+	mu sync.Mutex
+
+	s    *state
+	user database.User
+
+	width, height int
+	focus         tuiPane
+	showAll       bool
+	status        string
+
+	feeds   []tuiFeed
+	feedIdx int
+	posts   []postView
+	postIdx int
+	scroll  int
+}
+
+func handleTUI(s *state, _ command, user database.User) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("the reader needs an interactive terminal")
+	}
+
+	t := &tui{s: s, user: user}
+	if err := t.loadFeeds(); err != nil {
+		return err
+	}
+	if err := t.loadPosts(); err != nil {
+		return err
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("error setting up terminal: %v", err)
+	}
+	defer term.Restore(fd, oldState)
+	fmt.Print(ansiAltScreen + ansiHideCursor + ansiClear)
+	defer fmt.Print(ansiShowCursor + ansiMainScreen)
+
+	stopResize := onResize(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.draw()
+	})
+	defer stopResize()
+
+	buf := make([]byte, 64)
+	for {
+		t.mu.Lock()
+		t.draw()
+		t.mu.Unlock()
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil
+		}
+		t.mu.Lock()
+		quit := false
+		for _, key := range parseKeys(buf[:n]) {
+			if quit = t.handleKey(key); quit {
+				break
+			}
+		}
+		t.mu.Unlock()
+		if quit {
+			return nil
+		}
+	}
+}
+
+func (t *tui) loadFeeds() error {
+	follows, err := t.s.db.GetFeedFollowsForUser(context.Background(), t.user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting feed follows for user: %v", err)
+	}
+	all := tuiFeed{name: "All feeds"}
+	t.feeds = []tuiFeed{all}
+	for _, follow := range follows {
+		t.feeds = append(t.feeds, tuiFeed{
+			id:     uuid.NullUUID{UUID: follow.FeedID, Valid: true},
+			name:   follow.FeedName,
+			url:    follow.FeedUrl,
+			unread: follow.UnreadCount,
+		})
+		t.feeds[0].unread += follow.UnreadCount
+	}
+	t.feedIdx = min(t.feedIdx, len(t.feeds)-1)
+	return nil
+}
+
+func (t *tui) loadPosts() error {
+	filter := postFilter{
+		feedID:     t.feeds[t.feedIdx].id,
+		unreadOnly: !t.showAll,
+		sortBy:     sortPublished,
+		limit:      tuiPostLimit,
+	}
+	posts, err := queryPosts(context.Background(), t.s.db, t.user.ID, filter)
+	if err != nil {
+		return fmt.Errorf("error getting posts: %v", err)
+	}
+	t.posts = posts
+	t.postIdx = 0
+	t.scroll = 0
+	return nil
+}
+
+func (t *tui) selectedPost() (*postView, bool) {
+	if t.postIdx < 0 || t.postIdx >= len(t.posts) {
+		return nil, false
+	}
+	return &t.posts[t.postIdx], true
+}
+
+// handleKey applies a key press and reports whether the reader should quit.
+func (t *tui) handleKey(key string) bool {
+	t.status = ""
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "tab", "right", "l":
+		t.focus = min(t.focus+1, paneReader)
+	case "shift-tab", "left", "h":
+		t.focus = max(t.focus-1, paneFeeds)
+	case "down", "j":
+		t.move(1)
+	case "up", "k":
+		t.move(-1)
+	case "pgdown", " ":
+		t.move(t.bodyHeight() / 2)
+	case "pgup":
+		t.move(-t.bodyHeight() / 2)
+	case "enter":
+		switch t.focus {
+		case paneFeeds:
+			t.focus = panePosts
+		case panePosts:
+			t.focus = paneReader
+			if post, ok := t.selectedPost(); ok && !post.Read {
+				t.setRead(post, true)
+			}
+		}
+	case "m":
+		if post, ok := t.selectedPost(); ok {
+			t.setRead(post, !post.Read)
+		}
+	case "s":
+		if post, ok := t.selectedPost(); ok {
+			t.setStarred(post, !post.Starred)
+		}
+	case "o":
+		if post, ok := t.selectedPost(); ok {
+			if err := openBrowser(post.Url); err != nil {
+				t.status = fmt.Sprintf("error opening browser: %v", err)
+			} else {
+				t.status = "Opened " + post.Url
+			}
+		}
+	case "u":
+		t.showAll = !t.showAll
+		t.reload()
+	case "r":
+		t.reload()
+	}
+	return false
+}
+
+func (t *tui) move(delta int) {
+	switch t.focus {
+	case paneFeeds:
+		idx := clamp(t.feedIdx+delta, 0, len(t.feeds)-1)
+		if idx != t.feedIdx {
+			t.feedIdx = idx
+			if err := t.loadPosts(); err != nil {
+				t.status = err.Error()
+			}
+		}
+	case panePosts:
+		idx := clamp(t.postIdx+delta, 0, max(len(t.posts)-1, 0))
+		if idx != t.postIdx {
+			t.postIdx = idx
+			t.scroll = 0
+		}
+	case paneReader:
+		t.scroll = max(t.scroll+delta, 0)
+	}
+}
+
+func (t *tui) reload() {
+	if err := t.loadFeeds(); err != nil {
+		t.status = err.Error()
+		return
+	}
+	postIdx := t.postIdx
+	if err := t.loadPosts(); err != nil {
+		t.status = err.Error()
+		return
+	}
+	t.postIdx = clamp(postIdx, 0, max(len(t.posts)-1, 0))
+}
+
+func (t *tui) setRead(post *postView, read bool) {
+	var err error
+	if read {
+		err = t.s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+			UserID: t.user.ID,
+			PostID: post.ID,
+			ReadAt: time.Now(),
+		})
+	} else {
+		err = t.s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{
+			UserID: t.user.ID,
+			PostID: post.ID,
+		})
+	}
+	if err != nil {
+		t.status = fmt.Sprintf("error updating read state: %v", err)
+		return
+	}
+
+	post.Read = read
+	delta := int64(1)
+	if read {
+		delta = -1
+	}
+	for i := range t.feeds {
+		if !t.feeds[i].id.Valid || t.feeds[i].id.UUID == post.FeedID {
+			t.feeds[i].unread += delta
+		}
+	}
+}
+
+func (t *tui) setStarred(post *postView, starred bool) {
+	var err error
+	if starred {
+		err = t.s.db.StarPost(context.Background(), database.StarPostParams{
+			UserID:    t.user.ID,
+			PostID:    post.ID,
+			StarredAt: time.Now(),
+		})
+	} else {
+		err = t.s.db.UnstarPost(context.Background(), database.UnstarPostParams{
+			UserID: t.user.ID,
+			PostID: post.ID,
+		})
+	}
+	if err != nil {
+		t.status = fmt.Sprintf("error updating star: %v", err)
+		return
+	}
+	post.Starred = starred
+}
+
+func (t *tui) bodyHeight() int {
+	return max(t.height-2, 1)
+}
+
+// draw renders the whole screen. Callers must hold t.mu.
+func (t *tui) draw() {
+	if w, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		t.width, t.height = w, h
+	}
+	if t.width < 40 || t.height < 8 {
+		fmt.Print(ansiHome + ansiClear + "Terminal too small")
+		return
+	}
+
+	leftWidth := min(32, t.width/3)
+	rightWidth := t.width - leftWidth - 1
+	body := t.bodyHeight()
+	listHeight := max(body/3, 3)
+	readerHeight := body - listHeight - 1
+
+	feedLines := t.feedLines(leftWidth, body)
+	postLines := t.postLines(rightWidth, listHeight)
+	readerLines := t.readerLines(rightWidth, readerHeight)
+
+	var b bytes.Buffer
+	b.WriteString(ansiHome)
+	mode := "unread"
+	if t.showAll {
+		mode = "all posts"
+	}
+	b.WriteString(ansiReverse + pad(fmt.Sprintf(" gator — %s — %s", t.user.Name, mode), t.width) + ansiReset + "\r\n")
+	for row := 0; row < body; row++ {
+		b.WriteString(feedLines[row])
+		b.WriteString(ansiDim + "│" + ansiReset)
+		switch {
+		case row < listHeight:
+			b.WriteString(postLines[row])
+		case row == listHeight:
+			b.WriteString(ansiDim + strings.Repeat("─", rightWidth) + ansiReset)
+		default:
+			b.WriteString(readerLines[row-listHeight-1])
+		}
+		b.WriteString("\r\n")
+	}
+	status := t.status
+	if status == "" {
+		status = "tab/h/l pane  j/k move  enter open  m read  s star  o browser  u unread/all  r reload  q quit"
+	}
+	b.WriteString(ansiReverse + pad(" "+status, t.width) + ansiReset)
+	os.Stdout.Write(b.Bytes())
+}
+
+func (t *tui) feedLines(width, height int) []string {
+	lines := make([]string, height)
+	offset := max(t.feedIdx-height+1, 0)
+	for row := range lines {
+		i := offset + row
+		if i >= len(t.feeds) {
+			lines[row] = pad("", width)
+			continue
+		}
+		feed := t.feeds[i]
+		count := ""
+		if feed.unread > 0 {
+			count = fmt.Sprintf(" %d", feed.unread)
+		}
+		text := " " + truncate(feed.name, width-len(count)-2)
+		text = pad(text, width-len(count)) + count
+		lines[row] = t.styleRow(text, i == t.feedIdx, paneFeeds)
+	}
+	return lines
+}
+
+func (t *tui) postLines(width, height int) []string {
+	lines := make([]string, height)
+	offset := max(t.postIdx-height+1, 0)
+	for row := range lines {
+		i := offset + row
+		if i >= len(t.posts) {
+			if i == 0 {
+				lines[row] = pad(" No posts", width)
+			} else {
+				lines[row] = pad("", width)
+			}
+			continue
+		}
+		post := t.posts[i]
+		marker := "•"
+		if post.Read {
+			marker = " "
+		}
+		star := " "
+		if post.Starred {
+			star = "★"
+		}
+		text := fmt.Sprintf(" %s%s %s  %s", marker, star, post.PublishedAt.Format("Jan 02"), post.Title)
+		lines[row] = t.styleRow(pad(text, width), i == t.postIdx, panePosts)
+	}
+	return lines
+}
+
+func (t *tui) readerLines(width, height int) []string {
+	var content []string
+	if post, ok := t.selectedPost(); ok {
+		content = append(content,
+			ansiBold+truncate(" "+post.Title, width)+ansiReset,
+			ansiDim+truncate(fmt.Sprintf(" %s · %s", post.FeedName, post.PublishedAt.Format(time.RFC1123)), width)+ansiReset,
+			ansiDim+truncate(" "+post.Url, width)+ansiReset,
+			"",
+		)
+		body := renderHTML(post.Description, renderOptions{width: width - 2, ansi: true})
+		for _, line := range strings.Split(body, "\n") {
+			content = append(content, " "+line)
+		}
+	}
+	t.scroll = min(t.scroll, max(len(content)-height, 0))
+
+	lines := make([]string, height)
+	for row := range lines {
+		i := t.scroll + row
+		if i < len(content) {
+			// Every line is already cut to width and may carry styles.
+			lines[row] = content[i] + strings.Repeat(" ", max(width-visibleLen(content[i]), 0))
+		} else {
+			lines[row] = pad("", width)
+		}
+	}
+	return lines
+}
+
+func (t *tui) styleRow(text string, selected bool, pane tuiPane) string {
+	switch {
+	case selected && t.focus == pane:
+		return ansiReverse + text + ansiReset
+	case selected:
+		return ansiBold + text + ansiReset
+	default:
+		return text
+	}
+}
+
+// parseKeys turns raw terminal input into key names.
+func parseKeys(input []byte) []string {
+	var keys []string
+	for len(input) > 0 {
+		switch {
+		case bytes.HasPrefix(input, []byte("\033[A")):
+			keys, input = append(keys, "up"), input[3:]
+		case bytes.HasPrefix(input, []byte("\033[B")):
+			keys, input = append(keys, "down"), input[3:]
+		case bytes.HasPrefix(input, []byte("\033[C")):
+			keys, input = append(keys, "right"), input[3:]
+		case bytes.HasPrefix(input, []byte("\033[D")):
+			keys, input = append(keys, "left"), input[3:]
+		case bytes.HasPrefix(input, []byte("\033[Z")):
+			keys, input = append(keys, "shift-tab"), input[3:]
+		case bytes.HasPrefix(input, []byte("\033[5~")):
+			keys, input = append(keys, "pgup"), input[4:]
+		case bytes.HasPrefix(input, []byte("\033[6~")):
+			keys, input = append(keys, "pgdown"), input[4:]
+		case input[0] == '\033':
+			// Unknown escape sequence: drop the rest of this read.
+			return keys
+		case input[0] == '\r' || input[0] == '\n':
+			keys, input = append(keys, "enter"), input[1:]
+		case input[0] == '\t':
+			keys, input = append(keys, "tab"), input[1:]
+		case input[0] == 3:
+			keys, input = append(keys, "ctrl-c"), input[1:]
+		default:
+			r, size := utf8.DecodeRune(input)
+			keys, input = append(keys, string(r)), input[size:]
+		}
+	}
+	return keys
+}
+
+// openBrowser opens a post's URL. Only http and https URLs are opened, since
+// the URL comes from the feed and the openers also accept file paths and
+// other schemes.
+func openBrowser(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %v", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("not opening %q, only http and https URLs are allowed", rawURL)
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u.String())
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u.String())
+	default:
+		cmd = exec.Command("xdg-open", u.String())
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Reap the opener when it exits so it doesn't linger as a zombie while
+	// the reader stays open.
+	go cmd.Wait()
+	return nil
+}
+
+// truncate cuts s down to width runes on a single line. It also strips
+// control characters, since s is usually a title or name from a feed.
+func truncate(s string, width int) string {
+	s = strings.NewReplacer("\n", " ", "\t", " ").Replace(stripControl(s))
+	if width < 1 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
+
+// visibleLen counts the runes of s that are not part of ANSI escape codes.
+func visibleLen(s string) int {
+	n := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case r == '\033':
+			inEscape = true
+		case inEscape:
+			if r >= '@' && r <= '~' && r != '[' {
+				inEscape = false
+			}
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
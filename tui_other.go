@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// onResize is a no-op where there is no resize signal; the reader picks up
+// the new size on the next key press instead.
+func onResize(func()) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// The view types below are what commands print with --output. Their json
+// tags are part of gator's scripting interface, so rename them with care.
+
+type userView struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"current"`
+}
+
+type tokenView struct {
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type feedView struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Url           string     `json:"url"`
+	Owner         string     `json:"owner"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+// followView is a followed feed. FeedName is the user's title for the feed
+// if they set one, and Title is that custom title alone.
+type followView struct {
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	FeedUrl     string    `json:"feed_url"`
+	Title       string    `json:"title"`
+	Folder      string    `json:"folder"`
+	Position    int32     `json:"position"`
+	User        string    `json:"user"`
+	UnreadCount int64     `json:"unread_count"`
+	FollowedAt  time.Time `json:"followed_at"`
+}
+
+type postView struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	Description string    `json:"description"`
+	Content     string    `json:"content"`
+	PublishedAt time.Time `json:"published_at"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	Read        bool      `json:"read"`
+	Starred     bool      `json:"starred"`
+}
+
+type starredPostView struct {
+	postView
+	StarredAt time.Time `json:"starred_at"`
+}
+
+// taggedPostView is a post with the user's tags on it and the categories
+// its feed gave it.
+type taggedPostView struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	Tags        []string  `json:"tags"`
+	Categories  []string  `json:"categories"`
+}
+
+type tagView struct {
+	Tag   string `json:"tag"`
+	Posts int64  `json:"posts"`
+}
+
+// ruleView is a filter rule. Folder is null for rules that aren't limited
+// to a folder, since "" is the top level folder.
+type ruleView struct {
+	Name      string    `json:"name"`
+	Field     string    `json:"field"`
+	Match     string    `json:"match"`
+	Pattern   string    `json:"pattern"`
+	FeedUrl   string    `json:"feed_url,omitempty"`
+	Folder    *string   `json:"folder"`
+	Action    string    `json:"action"`
+	Tag       string    `json:"tag,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type applyRulesView struct {
+	DryRun bool          `json:"dry_run"`
+	Rules  []ruleRunView `json:"rules"`
+}
+
+type ruleRunView struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Matches int    `json:"matches"`
+}
+
+// removedRuleView is a deleted filter rule and the posts it no longer hides.
+type removedRuleView struct {
+	Rule     string             `json:"rule"`
+	Unhidden []unhiddenPostView `json:"unhidden"`
+}
+
+type unhiddenPostView struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Url   string    `json:"url"`
+}
+
+type savedSearchView struct {
+	Name        string    `json:"name"`
+	Query       string    `json:"query"`
+	FeedUrl     string    `json:"feed_url,omitempty"`
+	Folder      string    `json:"folder,omitempty"`
+	UnreadCount int64     `json:"unread_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type searchResultView struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	Rank        float32   `json:"rank"`
+	Snippet     string    `json:"snippet"`
+}
+
+type scrapeView struct {
+	FeedID    uuid.UUID `json:"feed_id"`
+	FeedName  string    `json:"feed_name"`
+	FeedUrl   string    `json:"feed_url"`
+	NewPosts  int       `json:"new_posts"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+type gcView struct {
+	DryRun bool         `json:"dry_run"`
+	Feeds  []gcFeedView `json:"feeds"`
+	Posts  int64        `json:"posts"`
+}
+
+type gcFeedView struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Url   string    `json:"url"`
+	Posts int64     `json:"posts"`
+}
+
+// retentionView is the retention policy. A feed's nil Days or Posts uses
+// the default, and 0 means no limit.
+type retentionView struct {
+	DefaultDays  int                 `json:"default_days"`
+	DefaultPosts int                 `json:"default_posts"`
+	Feeds        []feedRetentionView `json:"feeds"`
+}
+
+type feedRetentionView struct {
+	FeedName string `json:"feed_name"`
+	FeedUrl  string `json:"feed_url"`
+	Days     *int32 `json:"days"`
+	Posts    *int32 `json:"posts"`
+}
+
+type pruneView struct {
+	DryRun bool            `json:"dry_run"`
+	Feeds  []pruneFeedView `json:"feeds"`
+	Posts  int64           `json:"posts"`
+}
+
+type pruneFeedView struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Url   string    `json:"url"`
+	Posts int64     `json:"posts"`
+}
+
+type countView struct {
+	Action string `json:"action"`
+	Count  int64  `json:"count"`
+}
+
+type shareView struct {
+	Url string `json:"url"`
+}
+
+type messageView struct {
+	Message string `json:"message"`
+}
+
+func newUserView(user database.User, current string) userView {
+	return userView{
+		ID:        user.ID,
+		Name:      user.Name,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		Current:   user.Name == current,
+	}
+}
+
+func newFollowView(follow database.GetFeedFollowsForUserRow) followView {
+	return followView{
+		FeedID:      follow.FeedID,
+		FeedName:    follow.FeedName,
+		FeedUrl:     follow.FeedUrl,
+		Title:       follow.Title,
+		Folder:      follow.Folder,
+		Position:    follow.Position,
+		User:        follow.UserName,
+		UnreadCount: follow.UnreadCount,
+		FollowedAt:  follow.CreatedAt,
+	}
+}
+
+func newTokenView(token database.ApiToken) tokenView {
+	v := tokenView{
+		Name:      token.Name,
+		CreatedAt: token.CreatedAt,
+	}
+	if token.LastUsedAt.Valid {
+		v.LastUsedAt = &token.LastUsedAt.Time
+	}
+	return v
+}
+
+func newFeedView(feed database.Feed, owner string) feedView {
+	v := feedView{
+		ID:        feed.ID,
+		Name:      feed.Name,
+		Url:       feed.Url,
+		Owner:     owner,
+		CreatedAt: feed.CreatedAt,
+	}
+	if feed.LastFetchedAt.Valid {
+		v.LastFetchedAt = &feed.LastFetchedAt.Time
+	}
+	return v
+}
+
+func newStarredPostView(row database.GetStarredPostsForUserRow) starredPostView {
+	return starredPostView{
+		postView: postView{
+			ID:          row.ID,
+			Title:       row.Title,
+			Url:         row.Url,
+			Description: row.Description,
+			Content:     postContent(row.ContentText, row.Description),
+			PublishedAt: row.PublishedAt,
+			FetchedAt:   row.CreatedAt,
+			FeedID:      row.FeedID,
+			FeedName:    row.FeedName,
+			Starred:     true,
+		},
+		StarredAt: row.StarredAt,
+	}
+}
+
+func newTaggedPostView(row database.GetPostsByTagRow) taggedPostView {
+	v := taggedPostView{
+		ID:          row.ID,
+		Title:       row.Title,
+		Url:         row.Url,
+		PublishedAt: row.PublishedAt,
+		FeedID:      row.FeedID,
+		FeedName:    row.FeedName,
+		Tags:        row.Tags,
+		Categories:  row.Categories,
+	}
+	if v.Tags == nil {
+		v.Tags = []string{}
+	}
+	if v.Categories == nil {
+		v.Categories = []string{}
+	}
+	return v
+}
+
+func newRuleView(rule database.FilterRule, feedURL string) ruleView {
+	v := ruleView{
+		Name:      rule.Name,
+		Field:     rule.Field,
+		Match:     rule.MatchType,
+		Pattern:   rule.Pattern,
+		FeedUrl:   feedURL,
+		Action:    rule.Action,
+		Tag:       rule.Tag,
+		CreatedAt: rule.CreatedAt,
+	}
+	if rule.Folder.Valid {
+		v.Folder = &rule.Folder.String
+	}
+	return v
+}
+
+// describe summarises the rule in a line, e.g.
+// `noise: title contains "sponsored" in folder News -> hide`.
+func (v ruleView) describe() string {
+	verb := "contains"
+	if v.Match == ruleMatchRegex {
+		verb = "matches"
+	}
+	field := v.Field
+	if field == ruleFieldAny {
+		field = "any field"
+	}
+	desc := fmt.Sprintf("%s: %s %s %q", v.Name, field, verb, v.Pattern)
+	if v.FeedUrl != "" {
+		desc += " in feed " + v.FeedUrl
+	}
+	if v.Folder != nil {
+		if *v.Folder == "" {
+			desc += " in the top level folder"
+		} else {
+			desc += " in folder " + *v.Folder
+		}
+	}
+	desc += " -> " + v.Action
+	if v.Tag != "" {
+		desc += " " + v.Tag
+	}
+	return desc
+}
+
+func newSavedSearchView(saved database.SavedSearch, feedURL string, unread int64) savedSearchView {
+	return savedSearchView{
+		Name:        saved.Name,
+		Query:       saved.Query,
+		FeedUrl:     feedURL,
+		Folder:      saved.Folder.String,
+		UnreadCount: unread,
+		CreatedAt:   saved.CreatedAt,
+	}
+}
+
+// describe summarises the saved search in a line, e.g.
+// `cves: "CVE-2024" in folder Security`.
+func (v savedSearchView) describe() string {
+	desc := v.Name + ":"
+	if v.Query != "" {
+		desc += fmt.Sprintf(" %q", v.Query)
+	} else {
+		desc += " all posts"
+	}
+	if v.FeedUrl != "" {
+		desc += " in feed " + v.FeedUrl
+	}
+	if v.Folder != "" {
+		desc += " in folder " + v.Folder
+	}
+	return desc
+}
+
+func newSearchResultView(row database.SearchPostsForUserRow) searchResultView {
+	unmark := strings.NewReplacer(highlightStart, "", highlightStop, "")
+	return searchResultView{
+		ID:          row.ID,
+		Title:       row.Title,
+		Url:         row.Url,
+		PublishedAt: row.PublishedAt,
+		FeedID:      row.FeedID,
+		FeedName:    row.FeedName,
+		Rank:        row.Rank,
+		Snippet:     unmark.Replace(row.DescriptionHeadline),
+	}
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/awbalessa/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	sessionCookie = "gator_session"
+	webPostLimit  = 50
+)
+
+//go:embed web/templates/*.html web/static
+var webFiles embed.FS
+
+// webPage is the data every web UI template is executed with. Pages only
+// fill in the fields they show.
+type webPage struct {
+	Title   string
+	Path    string
+	User    string
+	Error   string
+	Name    string
+	Nav     []webNavItem
+	FeedID  string
+	Folder  string
+	ShowAll bool
+	Posts   []postView
+	Post    *postView
+	Next    string
+}
+
+// webNavItem is a line in the feed list: a folder heading, or a followed
+// feed when Follow is set. Depth is how deeply it is nested in folders.
+type webNavItem struct {
+	Folder string
+	Name   string
+	Depth  int
+	Unread int64
+	Follow *followView
+}
+
+// webNav lays out follows, sorted by folder, as a tree of folder headings
+// and feeds. Folder headings count the unread posts of every feed below.
+func webNav(follows []followView) []webNavItem {
+	var nav []webNavItem
+	var prev []string
+	headings := make(map[string]int)
+	for i := range follows {
+		parts := folderParts(follows[i].Folder)
+		common := 0
+		for common < len(prev) && common < len(parts) && prev[common] == parts[common] {
+			common++
+		}
+		for j := common; j < len(parts); j++ {
+			folder := strings.Join(parts[:j+1], "/")
+			headings[folder] = len(nav)
+			nav = append(nav, webNavItem{Folder: folder, Name: parts[j], Depth: j})
+		}
+		for j := range parts {
+			nav[headings[strings.Join(parts[:j+1], "/")]].Unread += follows[i].UnreadCount
+		}
+		nav = append(nav, webNavItem{
+			Name:   follows[i].FeedName,
+			Depth:  len(parts),
+			Unread: follows[i].UnreadCount,
+			Follow: &follows[i],
+		})
+		prev = parts
+	}
+	return nav
+}
+
+var webFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("Jan 2, 2006 15:04")
+	},
+}
+
+// parsePages parses each page template together with the shared layout.
+func parsePages() (map[string]*template.Template, error) {
+	names, err := fs.Glob(webFiles, "web/templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]*template.Template)
+	for _, name := range names {
+		base := strings.TrimPrefix(name, "web/templates/")
+		if base == "layout.html" {
+			continue
+		}
+		tmpl, err := template.New(base).Funcs(webFuncs).ParseFS(webFiles, "web/templates/layout.html", name)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template %s: %v", base, err)
+		}
+		pages[strings.TrimSuffix(base, ".html")] = tmpl
+	}
+	return pages, nil
+}
+
+func (srv *server) webRoutes(mux *http.ServeMux) {
+	static, err := fs.Sub(webFiles, "web/static")
+	if err != nil {
+		panic(err)
+	}
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	mux.HandleFunc("GET /{$}", srv.withWebUser(srv.handleWebIndex))
+	mux.HandleFunc("GET /posts/{id}", srv.withWebUser(srv.handleWebPost))
+	mux.HandleFunc("POST /posts/{id}/read", sameOrigin(srv.withWebUser(srv.handleWebMarkRead)))
+	mux.HandleFunc("POST /posts/{id}/star", sameOrigin(srv.withWebUser(srv.handleWebStar)))
+	mux.HandleFunc("POST /feeds", sameOrigin(srv.withWebUser(srv.handleWebAddFeed)))
+	mux.HandleFunc("POST /feeds/{id}/read", sameOrigin(srv.withWebUser(srv.handleWebMarkFeedRead)))
+	mux.HandleFunc("GET /login", srv.handleWebLogin)
+	mux.HandleFunc("POST /login", sameOrigin(srv.handleWebSignIn))
+	mux.HandleFunc("POST /logout", sameOrigin(srv.handleWebLogout))
+}
+
+// sessionUser returns the owner of the request's session cookie. An expired
+// session is sql.ErrNoRows.
+func (srv *server) sessionUser(r *http.Request) (database.User, error) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return database.User{}, errNoUser
+	}
+	return srv.s.db.GetUserBySessionToken(r.Context(), hashToken(cookie.Value))
+}
+
+// withWebUser is withUser for pages: without a user it sends the browser to
+// the login page instead of answering with a JSON error.
+func (srv *server) withWebUser(handler func(http.ResponseWriter, *http.Request, database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := srv.sessionUser(r)
+		if errors.Is(err, errNoUser) || errors.Is(err, sql.ErrNoRows) {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		} else if err != nil {
+			srv.renderError(w, http.StatusInternalServerError, "error getting user", err)
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+// sameOrigin rejects form posts made by other sites on the user's behalf.
+func sameOrigin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+			http.Error(w, "cross-site request rejected", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+				return
+			}
+		}
+		handler(w, r)
+	}
+}
+
+func (srv *server) render(w http.ResponseWriter, status int, page string, data webPage) {
+	tmpl, ok := srv.pages[page]
+	if !ok {
+		http.Error(w, "page not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("error rendering %s: %v", page, err)
+	}
+}
+
+func (srv *server) renderError(w http.ResponseWriter, status int, msg string, err error) {
+	if err != nil {
+		log.Printf("%s: %v", msg, err)
+	}
+	srv.render(w, status, "error", webPage{Title: "Error", Error: msg})
+}
+
+func (srv *server) handleWebIndex(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	page := webPage{
+		Title:   "Posts",
+		Path:    r.URL.RequestURI(),
+		User:    user.Name,
+		Error:   query.Get("error"),
+		FeedID:  query.Get("feed"),
+		Folder:  normalizeFolder(query.Get("folder")),
+		ShowAll: query.Get("all") == "1",
+	}
+
+	follows, err := srv.s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error getting feed follows for user", err)
+		return
+	}
+	views := make([]followView, 0, len(follows))
+	for _, follow := range follows {
+		views = append(views, newFollowView(follow))
+		if follow.FeedID.String() == page.FeedID {
+			page.Title = follow.FeedName
+		}
+	}
+	page.Nav = webNav(views)
+	if page.Folder != "" && page.FeedID == "" {
+		page.Title = page.Folder
+	}
+
+	filter := postFilter{
+		unreadOnly: !page.ShowAll,
+		sortBy:     sortPublished,
+		limit:      webPostLimit,
+	}
+	if page.FeedID != "" {
+		feedID, err := uuid.Parse(page.FeedID)
+		if err != nil {
+			srv.renderError(w, http.StatusBadRequest, "invalid feed ID", nil)
+			return
+		}
+		filter.feedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	} else if page.Folder != "" {
+		filter.folder = sql.NullString{String: page.Folder, Valid: true}
+	}
+	if after := query.Get("after"); after != "" {
+		if filter.cursor, err = decodeCursor(after); err != nil {
+			srv.renderError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+	}
+
+	page.Posts, err = queryPosts(r.Context(), srv.s.db, user.ID, filter)
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error getting posts from user", err)
+		return
+	}
+	if len(page.Posts) == webPostLimit {
+		page.Next = nextCursor(page.Posts, filter.sortBy)
+	}
+	srv.render(w, http.StatusOK, "index", page)
+}
+
+// handleWebPost shows a single post and marks it read, like opening it in
+// the terminal reader does.
+func (srv *server) handleWebPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		srv.renderError(w, http.StatusBadRequest, "invalid post ID", nil)
+		return
+	}
+	row, err := srv.s.db.GetPostForUser(r.Context(), database.GetPostForUserParams{
+		UserID: user.ID,
+		ID:     postID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		srv.renderError(w, http.StatusNotFound, "post not found", nil)
+		return
+	} else if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error getting post", err)
+		return
+	}
+
+	err = srv.s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: postID,
+		ReadAt: time.Now(),
+	})
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error marking post as read", err)
+		return
+	}
+
+	post := postView{
+		ID:          row.ID,
+		Title:       row.Title,
+		Url:         row.Url,
+		Description: row.Description,
+		Content:     postContent(row.ContentText, row.Description),
+		PublishedAt: row.PublishedAt,
+		FetchedAt:   row.CreatedAt,
+		FeedID:      row.FeedID,
+		FeedName:    row.FeedName,
+		Read:        true,
+		Starred:     row.IsStarred,
+	}
+	srv.render(w, http.StatusOK, "post", webPage{
+		Title:  post.Title,
+		Path:   r.URL.RequestURI(),
+		User:   user.Name,
+		FeedID: post.FeedID.String(),
+		Post:   &post,
+	})
+}
+
+func (srv *server) handleWebMarkRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		srv.renderError(w, http.StatusBadRequest, "invalid post ID", nil)
+		return
+	}
+	following, err := srv.s.db.IsFollowingPost(r.Context(), database.IsFollowingPostParams{
+		UserID: user.ID,
+		ID:     postID,
+	})
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error getting post", err)
+		return
+	}
+	if !following {
+		srv.renderError(w, http.StatusNotFound, "post not found", nil)
+		return
+	}
+	if r.FormValue("read") == "false" {
+		err = srv.s.db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{
+			UserID: user.ID,
+			PostID: postID,
+		})
+	} else {
+		err = srv.s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: postID,
+			ReadAt: time.Now(),
+		})
+	}
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error updating read state", err)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (srv *server) handleWebStar(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		srv.renderError(w, http.StatusBadRequest, "invalid post ID", nil)
+		return
+	}
+	if r.FormValue("starred") == "false" {
+		err = srv.s.db.UnstarPost(r.Context(), database.UnstarPostParams{
+			UserID: user.ID,
+			PostID: postID,
+		})
+	} else {
+		err = srv.s.db.StarPost(r.Context(), database.StarPostParams{
+			UserID:    user.ID,
+			PostID:    postID,
+			StarredAt: time.Now(),
+		})
+	}
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error updating star", err)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (srv *server) handleWebMarkFeedRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		srv.renderError(w, http.StatusBadRequest, "invalid feed ID", nil)
+		return
+	}
+	following, err := srv.s.db.IsFollowingFeed(r.Context(), database.IsFollowingFeedParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	})
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error getting feed", err)
+		return
+	}
+	if !following {
+		srv.renderError(w, http.StatusNotFound, "feed not found", nil)
+		return
+	}
+	_, err = srv.s.db.MarkFeedRead(r.Context(), database.MarkFeedReadParams{
+		UserID: user.ID,
+		ReadAt: time.Now(),
+		FeedID: feedID,
+	})
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error marking feed as read", err)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// handleWebAddFeed follows the feed with the submitted URL, adding it first
+// when nobody has added it yet.
+func (srv *server) handleWebAddFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	name := strings.TrimSpace(r.FormValue("name"))
+	feedURL := strings.TrimSpace(r.FormValue("url"))
+	if feedURL == "" {
+		http.Redirect(w, r, "/?error="+url.QueryEscape("a feed URL is required"), http.StatusSeeOther)
+		return
+	}
+
+	feed, err := srv.s.db.GetFeedByURL(r.Context(), feedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		if name == "" {
+			http.Redirect(w, r, "/?error="+url.QueryEscape("a name is required for a new feed"), http.StatusSeeOther)
+			return
+		}
+		feed, err = srv.s.db.CreateFeed(r.Context(), database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      name,
+			Url:       feedURL,
+			AddedBy:   uuid.NullUUID{UUID: user.ID, Valid: true},
+		})
+	}
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error adding feed", err)
+		return
+	}
+
+	_, err = srv.s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		http.Redirect(w, r, "/?error="+url.QueryEscape("you already follow "+feed.Name), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/?feed="+feed.ID.String(), http.StatusSeeOther)
+}
+
+func (srv *server) handleWebLogin(w http.ResponseWriter, r *http.Request) {
+	page := webPage{Title: "Sign in"}
+	if user, err := srv.sessionUser(r); err == nil {
+		page.User = user.Name
+	}
+	srv.render(w, http.StatusOK, "login", page)
+}
+
+// handleWebSignIn checks the submitted password and starts a session kept in
+// a cookie.
+func (srv *server) handleWebSignIn(w http.ResponseWriter, r *http.Request) {
+	page := webPage{Title: "Sign in", Name: r.FormValue("name")}
+	user, err := srv.s.db.GetUser(r.Context(), page.Name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		srv.renderError(w, http.StatusInternalServerError, "error getting user", err)
+		return
+	}
+	// An unknown name leaves user as the zero User, which checkPassword
+	// still spends a bcrypt comparison on.
+	if !checkPassword(user, r.FormValue("password")) {
+		page.Error = "Incorrect username or password."
+		srv.render(w, http.StatusUnauthorized, "login", page)
+		return
+	}
+
+	token, expires, err := createSession(r.Context(), srv.s.db, user)
+	if err != nil {
+		srv.renderError(w, http.StatusInternalServerError, "error signing in", err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (srv *server) handleWebLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if err = srv.s.db.DeleteSession(r.Context(), hashToken(cookie.Value)); err != nil {
+			srv.renderError(w, http.StatusInternalServerError, "error signing out", err)
+			return
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// redirectBack returns the browser to the page named in the form's "next"
+// field, which must be a path on this site.
+func redirectBack(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, localPath(r.FormValue("next")), http.StatusSeeOther)
+}
+
+// localPath returns next if it is a path on this site and "/" otherwise.
+// Browsers treat a backslash like a slash, so "/\evil.com" leads to another
+// site just like "//evil.com" does.
+func localPath(next string) string {
+	if len(next) > 1 && (next[1] == '/' || next[1] == '\\') {
+		return "/"
+	}
+	u, err := url.Parse(next)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return "/"
+	}
+	return next
+}
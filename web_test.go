@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestLocalPath(t *testing.T) {
+	tests := []struct {
+		next string
+		want string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"/feeds", "/feeds"},
+		{"/posts?feed=1&unread=true", "/posts?feed=1&unread=true"},
+		{"//evil.com", "/"},
+		{"/\\evil.com", "/"},
+		{"https://evil.com/", "/"},
+		{"javascript:alert(1)", "/"},
+		{"evil.com", "/"},
+		{"/\t/evil.com", "/"},
+	}
+	for _, tt := range tests {
+		if got := localPath(tt.next); got != tt.want {
+			t.Errorf("localPath(%q) = %q, want %q", tt.next, got, tt.want)
+		}
+	}
+}